@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/znsio/perfiz-cli/common/constants"
+	"github.com/znsio/perfiz-cli/common/gatling"
+	"github.com/znsio/perfiz-cli/common/path"
+	"github.com/znsio/perfiz-cli/common/report"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+var compareTolerances report.Tolerances
+
+func init() {
+	cmdCompare.Flags().Float64Var(&compareTolerances.ResponseTime, "response-time-tolerance", 10, "percent by which p50, p95 and p99 response times may grow before it counts as a regression")
+	cmdCompare.Flags().Float64Var(&compareTolerances.Throughput, "throughput-tolerance", 10, "percent by which throughput may drop before it counts as a regression")
+	cmdCompare.Flags().Float64Var(&compareTolerances.ErrorRate, "error-rate-tolerance", 1, "percentage points by which the error rate may grow before it counts as a regression")
+	rootCmd.AddCommand(cmdCompare)
+}
+
+var cmdCompare = &cobra.Command{
+	Use:   "compare <baseline run> <candidate run>",
+	Short: "Compare the results of two test runs",
+	Long: `Compare p50, p95 and p99 response times, throughput and error rate of every request between a baseline run and a candidate run.
+                A run is a run directory, by path or by name under ` + constants.GATLING_RESULTS_DIR + `, or a ` + report.SUMMARY_REPORT + ` file.
+                Exits with ` + strconv.Itoa(constants.EXIT_CODE_ASSERTIONS_FAILED) + ` when the candidate regressed beyond the tolerances.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		baseline, baselineErr := runStatistics(args[0])
+		if baselineErr != nil {
+			log.Fatalln("Unable to read baseline run " + args[0] + ": " + baselineErr.Error())
+		}
+		candidate, candidateErr := runStatistics(args[1])
+		if candidateErr != nil {
+			log.Fatalln("Unable to read candidate run " + args[1] + ": " + candidateErr.Error())
+		}
+		comparison := report.Compare(baseline, candidate, compareTolerances)
+		comparison.WriteTable(os.Stdout)
+		if comparison.Regressions > 0 {
+			exitWithCode(constants.EXIT_CODE_ASSERTIONS_FAILED, strconv.Itoa(comparison.Regressions)+" regression(s) beyond tolerances.")
+		}
+		fmt.Println("No regressions beyond tolerances.")
+	},
+}
+
+// runStatistics reads the statistics of a run from its summary, or from its
+// simulation.log for runs made before summaries were written.
+func runStatistics(run string) (*gatling.Statistics, error) {
+	run, runErr := resolveRun(run)
+	if runErr != nil {
+		return nil, runErr
+	}
+	_, summaryErr := os.Stat(filepath.Join(run, report.SUMMARY_REPORT))
+	if !path.IsDir(run) || summaryErr == nil {
+		summary, err := report.ReadSummary(run)
+		if err != nil {
+			return nil, err
+		}
+		return summary.Statistics, nil
+	}
+	simulationLog, parseErr := gatling.ParseRunDir(run)
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	return gatling.ComputeStatistics(simulationLog), nil
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/znsio/perfiz-cli/common/configuration"
+	"io/ioutil"
+	"log"
+)
+
+var schemaOutputFile string
+
+func init() {
+	cmdConfigSchema.Flags().StringVarP(&schemaOutputFile, "output", "o", "", "write the schema to this file instead of stdout")
+	cmdConfig.AddCommand(cmdConfigSchema)
+	rootCmd.AddCommand(cmdConfig)
+}
+
+var cmdConfig = &cobra.Command{
+	Use:   "config",
+	Short: "Perfiz Config utilities",
+	Long:  `Utilities for working with perfiz.yml`,
+}
+
+var cmdConfigSchema = &cobra.Command{
+	Use:   "schema",
+	Short: "Print JSON Schema of perfiz.yml",
+	Long: `Print the JSON Schema (draft 2020-12) of perfiz.yml for editor autocompletion and validation.
+                For the VS Code YAML extension add "# yaml-language-server: $schema=<schema file>" to the top of perfiz.yml.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		schema, schemaErr := configuration.Schema()
+		if schemaErr != nil {
+			log.Fatalln(schemaErr)
+		}
+		if schemaOutputFile == "" {
+			fmt.Println(string(schema))
+			return
+		}
+		if err := ioutil.WriteFile(schemaOutputFile, append(schema, '\n'), 0644); err != nil {
+			log.Fatalln("Error writing schema to " + schemaOutputFile + ": " + err.Error())
+		}
+		log.Println("Perfiz Config schema written to " + schemaOutputFile)
+	},
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/znsio/perfiz-cli/common/command"
 	"github.com/znsio/perfiz-cli/common/constants"
 	"github.com/znsio/perfiz-cli/common/environment"
 	"github.com/znsio/perfiz-cli/common/version"
@@ -24,8 +25,12 @@ var cmdDiagnostics = &cobra.Command{
 		perfizVersion := version.GetPerfizVersion()
 		log.Println("Perfiz Version: " + perfizVersion)
 		log.Println("Perfiz Cli Version: " + constants.PERFIZ_CLI_VERSION)
-		log.Println("Docker version: " + environment.GetCommandVersion("docker"))
-		log.Println("docker-compose version: " + environment.GetCommandVersion("docker-compose"))
+		for _, containerRuntime := range environment.RUNTIMES {
+			logVersion(containerRuntime.String(), containerRuntime.Version())
+			for _, compose := range containerRuntime.Composes() {
+				logVersion(compose.String(), compose.Version())
+			}
+		}
 		log.Println("OS: " + runtime.GOOS)
 		log.Println("Arch: " + runtime.GOARCH)
 		perfizFolderStats, perfizFolderStatsErr := os.Stat(constants.PERFIZ_FOLDER)
@@ -36,4 +41,13 @@ var cmdDiagnostics = &cobra.Command{
 		}
 		log.Println("************* DIAGNOSTICS COMPLETED ******************")
 	},
-}
\ No newline at end of file
+}
+
+func logVersion(name string, version command.Command) {
+	versionOutput, versionErr := version.Execute()
+	if versionErr != nil {
+		log.Println(name + " version: not found. " + versionErr.Error())
+	} else {
+		log.Println(name + " version: " + string(versionOutput))
+	}
+}
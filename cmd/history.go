@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/znsio/perfiz-cli/common/configuration"
+	"github.com/znsio/perfiz-cli/common/constants"
+	"github.com/znsio/perfiz-cli/common/history"
+	"log"
+	"os"
+	"time"
+)
+
+var historyFilter history.Filter
+var historySince string
+var historyTrends bool
+
+func init() {
+	cmdHistory.Flags().StringVar(&historyFilter.ConfigFile, "config", "", "only runs of this config file")
+	cmdHistory.Flags().StringVar(&historyFilter.ConfigHash, "config-hash", "", "only runs of the config with this hash, whichever file or profile it came from")
+	cmdHistory.Flags().StringVar(&historyFilter.GitCommit, "commit", "", "only runs at this git commit, or at commits starting with it")
+	cmdHistory.Flags().StringVar(&historyFilter.Outcome, "outcome", "", "only runs with this outcome, for example passed or \"assertions failed\"")
+	cmdHistory.Flags().StringVar(&historyFilter.Profile, "profile", "", "only runs with this profile applied")
+	cmdHistory.Flags().StringVar(&historySince, "since", "", "only runs started within this long, for example 7d or 12h")
+	cmdHistory.Flags().IntVar(&historyFilter.Last, "last", 20, "show at most this many of the most recent runs, 0 for all")
+	cmdHistory.Flags().BoolVar(&historyTrends, "trends", false, "also show how error rate, response times and throughput developed over the runs")
+	rootCmd.AddCommand(cmdHistory)
+}
+
+var cmdHistory = &cobra.Command{
+	Use:   "history",
+	Short: "List past test runs",
+	Long: `List the runs of perfiz test recorded in ` + constants.HISTORY_FILE + ` with their config, git commit, outcome and headline metrics.
+                Filter them by config, commit, outcome, profile or age and see trends across them.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if historySince != "" {
+			since, sinceErr := configuration.ParseDuration(historySince)
+			if sinceErr != nil {
+				log.Fatalln("Invalid --since: " + sinceErr.Error())
+			}
+			historyFilter.Since = time.Now().Add(-since)
+		}
+		entries, readErr := history.Read(constants.HISTORY_FILE)
+		if readErr != nil {
+			log.Fatalln("Unable to read history: " + readErr.Error())
+		}
+		entries = historyFilter.Apply(entries)
+		if len(entries) == 0 {
+			fmt.Println("No test runs found in " + constants.HISTORY_FILE + ".")
+			return
+		}
+		history.WriteTable(os.Stdout, entries)
+		if historyTrends {
+			fmt.Println()
+			history.WriteTrends(os.Stdout, entries)
+		}
+	},
+}
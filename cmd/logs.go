@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"errors"
+	"github.com/spf13/cobra"
+	"github.com/znsio/perfiz-cli/common/constants"
+	env "github.com/znsio/perfiz-cli/common/environment"
+	"github.com/znsio/perfiz-cli/common/stream"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+var logsFollow bool
+var logsSince string
+
+func init() {
+	cmdLogs.Flags().BoolVarP(&logsFollow, "follow", "f", false, "keep printing new log lines")
+	cmdLogs.Flags().StringVar(&logsSince, "since", "", "show logs since a timestamp, such as 2021-08-01T13:00:00, or a relative time, such as 10m")
+	rootCmd.AddCommand(cmdLogs)
+}
+
+var cmdLogs = &cobra.Command{
+	Use:   "logs [service]",
+	Short: "Show logs of Perfiz Containers",
+	Long: `Show the logs of a service of the Perfiz Monitoring Stack, such as grafana, or of the ` + constants.GATLING_CONTAINER + ` test container of this project with 'gatling'.
+                Without a service, shows the logs of the whole stack and of running test containers of this project.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		service := ""
+		if len(args) == 1 {
+			service = args[0]
+		}
+		if service == "gatling" || service == constants.GATLING_CONTAINER {
+			testContainers, listErr := runningTestContainers()
+			if listErr != nil {
+				exitWithCode(constants.EXIT_CODE_INFRASTRUCTURE_ERROR, "Unable to list test containers: "+listErr.Error())
+			}
+			if len(testContainers) == 0 {
+				log.Fatalln("No " + constants.GATLING_CONTAINER + " test container is running for this project.")
+			}
+			if len(testContainers) == 1 {
+				runLogs(gatlingLogs(testContainers[0]))
+				return
+			}
+			logsCommands := map[string]*exec.Cmd{}
+			for _, testContainer := range testContainers {
+				logsCommands[testContainer] = gatlingLogs(testContainer)
+			}
+			streamLogs(logsCommands)
+			return
+		}
+
+		perfizHome := env.GetEnvVariable(constants.PERFIZ_HOME_ENV_VARIABLE)
+		composeLogs := composeLogs(perfizHome, service)
+		if service != "" {
+			runLogs(composeLogs)
+			return
+		}
+		testContainers, listErr := runningTestContainers()
+		if listErr != nil {
+			exitWithCode(constants.EXIT_CODE_INFRASTRUCTURE_ERROR, "Unable to list test containers: "+listErr.Error())
+		}
+		if len(testContainers) == 0 {
+			runLogs(composeLogs)
+			return
+		}
+		logsCommands := map[string]*exec.Cmd{"perfiz": composeLogs}
+		for _, testContainer := range testContainers {
+			logsCommands[testContainer] = gatlingLogs(testContainer)
+		}
+		streamLogs(logsCommands)
+	},
+}
+
+func composeLogs(perfizHome string, service string) *exec.Cmd {
+	args := append([]string{"logs"}, logsOptions()...)
+	if service != "" {
+		args = append(args, service)
+	}
+	return dockerCompose(getCompose(), perfizHome, args...)
+}
+
+func gatlingLogs(testContainer string) *exec.Cmd {
+	args := append([]string{"logs"}, logsOptions()...)
+	return getRuntime().Command(append(args, testContainer)...)
+}
+
+func logsOptions() []string {
+	var options []string
+	if logsFollow {
+		options = append(options, "--follow")
+	}
+	if logsSince != "" {
+		options = append(options, "--since", logsSince)
+	}
+	return options
+}
+
+// runningTestContainers returns the names of the test containers running for the
+// project in the working directory. Each run names its container after itself, so
+// they are told apart from other containers by name prefix and from the runs of other
+// projects by label. The error of ps carries what ps printed to stderr.
+func runningTestContainers() ([]string, error) {
+	workingDir, _ := os.Getwd()
+	containers, err := env.ListContainers(getRuntime().Create("ps",
+		"--filter", "name="+constants.GATLING_CONTAINER+"-",
+		"--filter", "label="+constants.PROJECT_LABEL+"="+workingDir,
+		"--format", env.PS_FORMAT))
+	if err != nil {
+		if exitErr, isExitErr := err.(*exec.ExitError); isExitErr && len(exitErr.Stderr) > 0 {
+			return nil, errors.New(err.Error() + ": " + strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, err
+	}
+	var names []string
+	for _, container := range containers {
+		if isTestContainer(container.Name) {
+			names = append(names, container.Name)
+		}
+	}
+	return names, nil
+}
+
+func isTestContainer(name string) bool {
+	return strings.HasPrefix(name, constants.GATLING_CONTAINER+"-")
+}
+
+func runLogs(logsCommand *exec.Cmd) {
+	log.Println(logsCommand)
+	logsCommand.Stdout = os.Stdout
+	logsCommand.Stderr = os.Stderr
+	if err := logsCommand.Run(); err != nil {
+		log.Fatalln(err.Error())
+	}
+}
+
+// streamLogs prints the logs of several commands together, each line prefixed with the
+// name of the command it came from.
+func streamLogs(logsCommands map[string]*exec.Cmd) {
+	var streams []stream.Stream
+	for name, logsCommand := range logsCommands {
+		log.Println(logsCommand)
+		reader, writer := io.Pipe()
+		logsCommand.Stdout = writer
+		logsCommand.Stderr = writer
+		if err := logsCommand.Start(); err != nil {
+			log.Fatalln(err.Error())
+		}
+		go func(logsCommand *exec.Cmd, writer *io.PipeWriter) {
+			writer.CloseWithError(logsCommand.Wait())
+		}(logsCommand, writer)
+		streams = append(streams, stream.Stream{Name: name, Reader: reader})
+	}
+	stream.New(log.New(os.Stdout, "", 0), false).Consume(streams...)
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/znsio/perfiz-cli/common/command"
+	env "github.com/znsio/perfiz-cli/common/environment"
+)
+
+// failingPsRuntime is a runtime whose ps fails the way docker does without a daemon.
+type failingPsRuntime struct {
+	env.Runtime
+}
+
+func (runtime failingPsRuntime) Create(args ...string) command.Command {
+	return command.Create("sh", "-c", "echo 'Cannot connect to the Docker daemon' >&2; exit 1")
+}
+
+func Test_runningTestContainers_ReturnsErrorOfPs(t *testing.T) {
+	containerRuntime = failingPsRuntime{}
+	defer func() { containerRuntime = nil }()
+	testContainers, err := runningTestContainers()
+	assert.Nil(t, testContainers)
+	assert.Equal(t, "exit status 1: Cannot connect to the Docker daemon", err.Error())
+}
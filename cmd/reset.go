@@ -2,37 +2,131 @@ package cmd
 
 import (
 	"github.com/spf13/cobra"
+	"github.com/znsio/perfiz-cli/common/archive"
 	"github.com/znsio/perfiz-cli/common/constants"
 	"github.com/znsio/perfiz-cli/common/path"
 	"log"
 	"os"
-	"os/exec"
+	"path/filepath"
+	"strings"
 )
 
+// dataStore is one of the perfiz/*_data directories the Perfiz containers keep their
+// data in.
+type dataStore struct {
+	name string
+	dir  string
+}
+
+var dataStores = []dataStore{
+	{"grafana", constants.GRAFANA_DATA_DIR},
+	{"influxdb", constants.INFLUXDB_DATA_DIR},
+	{"prometheus", constants.PROMETHEUS_DATA_DIR},
+	{"gatling", constants.GATLING_DATA_DIR},
+}
+
+var resetStores = map[string]*bool{}
+var resetDryRun bool
+var resetYes bool
+var resetArchive string
+
 func init() {
+	for _, store := range dataStores {
+		resetStores[store.name] = cmdReset.Flags().Bool(store.name, false, "reset "+store.dir)
+	}
+	cmdReset.Flags().BoolVar(&resetDryRun, "dry-run", false, "list what would be deleted and its size without deleting anything")
+	cmdReset.Flags().BoolVarP(&resetYes, "yes", "y", false, "do not ask for confirmation")
+	cmdReset.Flags().StringVar(&resetArchive, "archive", "", "archive the data to this .tar.gz file before deleting it")
 	rootCmd.AddCommand(cmdReset)
 }
 
 var cmdReset = &cobra.Command{
 	Use:   "reset",
 	Short: "removes project specific grafana and prometheus data",
-	Long:  `removes <your project folder>/perfiz/*_data to reset Grafana, InfluxDB and Prometheus specific to that project`,
-	Args:  cobra.MinimumNArgs(0),
+	Long: `removes <your project folder>/perfiz/*_data to reset Grafana, InfluxDB and Prometheus specific to that project.
+                Choose what to reset with --grafana, --influxdb, --prometheus and --gatling, all of it when none is given.`,
+	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		dockerNetworkCheck := exec.Command("docker", "network", "inspect", "perfiz-network")
+		if !path.IsDir(constants.PERFIZ_FOLDER) {
+			log.Fatalln("Could not find perfiz folder, please run 'reset' command inside your project where the perfiz folder exists.")
+		}
 
-		_, dockerNetworkCheckError := dockerNetworkCheck.Output()
-		if dockerNetworkCheckError == nil {
-			log.Fatalln("Perfiz Containers seem to be running. Please run 'stop' command before running 'reset'.")
+		var dataDirs []string
+		var total int64
+		for _, store := range selectedDataStores() {
+			if _, err := os.Stat(store.dir); err != nil {
+				log.Println(store.dir + " does not exist. Skipping.")
+				continue
+			}
+			size := path.Size(store.dir)
+			total += size
+			log.Println(store.dir + " (" + path.HumanSize(size) + ")")
+			dataDirs = append(dataDirs, store.dir)
+		}
+		if len(dataDirs) == 0 {
+			log.Println("Nothing to reset.")
+			return
+		}
+		if resetDryRun {
+			log.Println("Dry run. " + path.HumanSize(total) + " would be deleted.")
+			return
 		}
 
-		if !path.IsDir(constants.PERFIZ_FOLDER) {
-			log.Fatalln("Could not find perfiz folder, please run 'reset' command inside your project where the perfiz folder exists.")
+		checkPerfizStopped("reset")
+		if !resetYes && !confirm("Delete "+strings.Join(dataDirs, ", ")+" ("+path.HumanSize(total)+")?") {
+			log.Fatalln("Reset cancelled. Run with --yes to reset without confirmation.")
 		}
 
-		for _, dataDirPath := range []string{constants.PERFIZ_FOLDER + "/grafana_data", constants.PERFIZ_FOLDER + "/influxdb_data", constants.PERFIZ_FOLDER + "/prometheus_data", constants.PERFIZ_FOLDER + "/gatling_data"} {
+		if resetArchive != "" {
+			for _, dataDirPath := range dataDirs {
+				if isWithinDir(dataDirPath, resetArchive) {
+					log.Fatalln("Archive " + resetArchive + " would be deleted along with " + dataDirPath + ". Please archive to another location.")
+				}
+			}
+			log.Println("Archiving " + strings.Join(dataDirs, ", ") + " to " + resetArchive)
+			if err := archive.CreateFrom(resetArchive, dataDirs...); err != nil {
+				log.Fatalln("Error archiving data, nothing was deleted: " + err.Error())
+			}
+		}
+		for _, dataDirPath := range dataDirs {
 			log.Println("Deleting " + dataDirPath)
-			os.RemoveAll(dataDirPath)
+			if err := os.RemoveAll(dataDirPath); err != nil {
+				log.Println("Error deleting " + dataDirPath + ": " + err.Error())
+			}
 		}
 	},
-}
\ No newline at end of file
+}
+
+// checkPerfizStopped exits when the Perfiz containers may be writing to their data.
+// Without a container runtime, and no Docker host to reach, they can not be running.
+func checkPerfizStopped(commandName string) {
+	if os.Getenv(constants.DOCKER_HOST_ENV_VARIABLE) == "" {
+		if _, err := detectRuntime(); err != nil {
+			log.Println("Perfiz Containers are not running. " + err.Error())
+			return
+		}
+	}
+	if isPerfizNetworkUp() {
+		log.Fatalln("Perfiz Containers seem to be running. Please run 'stop' command before running '" + commandName + "'.")
+	}
+}
+
+// selectedDataStores are the data stores chosen on the command line, or all of them.
+func selectedDataStores() []dataStore {
+	var selected []dataStore
+	for _, store := range dataStores {
+		if *resetStores[store.name] {
+			selected = append(selected, store)
+		}
+	}
+	if len(selected) == 0 {
+		return dataStores
+	}
+	return selected
+}
+
+func isWithinDir(dir string, pathFile string) bool {
+	absoluteDir, dirErr := filepath.Abs(dir)
+	absolutePath, pathErr := filepath.Abs(pathFile)
+	return dirErr == nil && pathErr == nil && strings.HasPrefix(absolutePath, absoluteDir+string(filepath.Separator))
+}
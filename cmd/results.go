@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"errors"
+	"github.com/spf13/cobra"
+	"github.com/znsio/perfiz-cli/common/configuration"
+	"github.com/znsio/perfiz-cli/common/constants"
+	"github.com/znsio/perfiz-cli/common/path"
+	"github.com/znsio/perfiz-cli/common/report"
+	"github.com/znsio/perfiz-cli/common/retention"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var pruneRetention configuration.Retention
+var pruneDryRun bool
+
+func init() {
+	cmdResultsPrune.Flags().IntVar(&pruneRetention.KeepLast, "keep-last", 0, "keep this many of the most recent runs")
+	cmdResultsPrune.Flags().StringVar((*string)(&pruneRetention.OlderThan), "older-than", "", "prune only runs older than this, for example 30d or 12h")
+	cmdResultsPrune.Flags().BoolVar(&pruneRetention.KeepTagged, "keep-tagged", false, "keep runs that have been tagged, whatever their age")
+	cmdResultsPrune.Flags().BoolVar(&pruneDryRun, "dry-run", false, "list the runs that would be pruned without removing them")
+	cmdResults.AddCommand(cmdResultsPrune)
+	cmdResults.AddCommand(cmdResultsTag)
+	rootCmd.AddCommand(cmdResults)
+}
+
+var cmdResults = &cobra.Command{
+	Use:   "results",
+	Short: "Manage test results",
+	Long:  `Manage the runs in ` + constants.GATLING_RESULTS_DIR,
+}
+
+var cmdResultsPrune = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove old test runs",
+	Long: `Remove runs from ` + constants.GATLING_RESULTS_DIR + ` that are neither among the --keep-last most recent runs
+                nor newer than --older-than and, with --keep-tagged, have no tags. Grafana, Prometheus and InfluxDB data is left alone.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		policy, policyErr := retention.PolicyFrom(&pruneRetention)
+		if policyErr != nil {
+			log.Fatalln("Invalid retention: " + policyErr.Error() + ". Please set --keep-last or --older-than")
+		}
+		if err := pruneResults(policy, pruneDryRun); err != nil {
+			log.Fatalln("Error pruning results: " + err.Error())
+		}
+	},
+}
+
+var cmdResultsTag = &cobra.Command{
+	Use:   "tag <run> <tag>...",
+	Short: "Tag a test run",
+	Long: `Add tags to a run, given by path or by name under ` + constants.GATLING_RESULTS_DIR + `, for example to mark it as a baseline.
+                Tagged runs are kept by results prune --keep-tagged.`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		runDir, runErr := resolveRun(args[0])
+		if runErr != nil {
+			log.Fatalln("Run " + args[0] + " " + runErr.Error())
+		}
+		if err := report.AddTags(runDir, args[1:]); err != nil {
+			log.Fatalln("Unable to tag run " + args[0] + ": " + err.Error())
+		}
+		log.Println("Tagged " + runDir + " with " + strings.Join(args[1:], ", "))
+	},
+}
+
+func pruneResults(policy retention.Policy, dryRun bool) error {
+	pruned, pruneErr := retention.Prune(constants.GATLING_RESULTS_DIR, policy, time.Now(), dryRun)
+	var freed int64
+	for _, run := range pruned {
+		freed += run.Size
+		if dryRun {
+			log.Println("Would remove " + run.Dir + " (" + path.HumanSize(run.Size) + ")")
+		} else {
+			log.Println("Removed " + run.Dir + " (" + path.HumanSize(run.Size) + ")")
+		}
+	}
+	if pruneErr != nil {
+		return pruneErr
+	}
+	if dryRun {
+		log.Println(strconv.Itoa(len(pruned)) + " run(s) would be pruned, freeing " + path.HumanSize(freed) + ".")
+		return nil
+	}
+	log.Println(strconv.Itoa(len(pruned)) + " run(s) pruned, freeing " + path.HumanSize(freed) + ".")
+	return nil
+}
+
+// resolveRun finds a run directory or summary file by path, or by name in the results
+// folder.
+func resolveRun(run string) (string, error) {
+	if _, err := os.Stat(run); err == nil {
+		return run, nil
+	}
+	resultsRun := filepath.Join(constants.GATLING_RESULTS_DIR, run)
+	if _, err := os.Stat(resultsRun); err != nil {
+		return "", errors.New("not found, neither as a path nor in " + constants.GATLING_RESULTS_DIR)
+	}
+	return resultsRun, nil
+}
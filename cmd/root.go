@@ -1,9 +1,17 @@
 package cmd
 
 import (
+	"bufio"
+	"crypto/tls"
 	"fmt"
 	"github.com/spf13/cobra"
+	"github.com/znsio/perfiz-cli/common/constants"
+	"github.com/znsio/perfiz-cli/common/dockerapi"
+	env "github.com/znsio/perfiz-cli/common/environment"
+	"log"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
 var rootCmd = &cobra.Command{
@@ -11,11 +19,147 @@ var rootCmd = &cobra.Command{
 	Short: "A Dockerised Performance Test Setup",
 	Long: `A Dockerised API Performance Test Setup based on Gatling with Grafana Dashboards and Prometheus Monitoring.
                 Complete documentation is available at https://perfiz.com`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Setting DOCKER_HOST passes --docker-host on to compose and the docker CLI.
+		if dockerHostChoice != "" {
+			os.Setenv(constants.DOCKER_HOST_ENV_VARIABLE, dockerHostChoice)
+		}
+	},
+}
+
+var runtimeChoice string
+var dockerHostChoice string
+var composeChoice string
+var containerRuntime env.Runtime
+var dockerClient *dockerapi.Client
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&runtimeChoice, "runtime", "", "container runtime to use, docker or podman. Detected when not set. Defaults to the "+constants.RUNTIME_ENV_VARIABLE+" environment variable")
+	rootCmd.PersistentFlags().StringVar(&dockerHostChoice, "docker-host", "", "Docker host to run Perfiz on, such as ssh://user@loadgen or tcp://loadgen:2375. Defaults to the "+constants.DOCKER_HOST_ENV_VARIABLE+" environment variable, then the docker context in use")
+	rootCmd.PersistentFlags().StringVar(&composeChoice, "compose", "", "Docker Compose to use, v1 for docker-compose or v2 for docker compose, or podman-compose and podman compose with podman. Detected when not set. Defaults to the "+constants.COMPOSE_ENV_VARIABLE+" environment variable")
 }
 
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(constants.EXIT_CODE_ERROR)
+	}
+}
+
+// exitWithCode is log.Fatalln with one of the documented exit codes in place of 1.
+func exitWithCode(code int, v ...interface{}) {
+	log.Println(v...)
+	os.Exit(code)
+}
+
+// getRuntime is the container runtime chosen with --runtime or PERFIZ_RUNTIME, or else
+// the one installed. It is detected once per command.
+func getRuntime() env.Runtime {
+	runtime, err := detectRuntime()
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+	return runtime
+}
+
+// detectRuntime is getRuntime for commands that can do without a container runtime.
+func detectRuntime() (env.Runtime, error) {
+	if containerRuntime != nil {
+		return containerRuntime, nil
+	}
+	choice := runtimeChoice
+	if choice == "" {
+		choice = os.Getenv(constants.RUNTIME_ENV_VARIABLE)
+	}
+	runtime, err := env.DetectRuntime(choice, env.RUNTIMES)
+	if err != nil {
+		return nil, err
+	}
+	log.Println("Using " + runtime.String())
+	containerRuntime = runtime
+	return containerRuntime, nil
+}
+
+// getDockerClient is a client of the Docker Engine API at --docker-host or DOCKER_HOST,
+// else at the host of the docker context in use, or else where the container runtime
+// serves it.
+func getDockerClient() *dockerapi.Client {
+	if dockerClient != nil {
+		return dockerClient
+	}
+	host := os.Getenv(constants.DOCKER_HOST_ENV_VARIABLE)
+	var tlsConfig *tls.Config
+	if host != "" && os.Getenv(constants.DOCKER_TLS_VERIFY_ENV_VARIABLE) != "" {
+		certPath := os.Getenv(constants.DOCKER_CERT_PATH_ENV_VARIABLE)
+		if certPath == "" {
+			certPath = dockerConfigDir()
+		}
+		var tlsErr error
+		if tlsConfig, tlsErr = dockerapi.LoadTLSConfig(certPath, true); tlsErr != nil {
+			log.Fatalln(tlsErr.Error())
+		}
 	}
+	if host == "" && getRuntime().Name() == env.DOCKER.Name() {
+		endpoint, contextErr := dockerapi.ContextEndpoint(dockerConfigDir(), os.Getenv(constants.DOCKER_CONTEXT_ENV_VARIABLE))
+		if contextErr != nil {
+			log.Fatalln(contextErr.Error())
+		}
+		host = endpoint.Host
+		tlsConfig = endpoint.TLSConfig
+	}
+	if host == "" {
+		host = getRuntime().DefaultHost()
+	}
+	client, err := dockerapi.NewClient(host, tlsConfig)
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+	if client.IsRemote() {
+		log.Println("Using Docker host " + host)
+	}
+	dockerClient = client
+	return dockerClient
+}
+
+func dockerConfigDir() string {
+	if dockerConfig := os.Getenv(constants.DOCKER_CONFIG_ENV_VARIABLE); dockerConfig != "" {
+		return dockerConfig
+	}
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".docker")
+}
+
+// isPerfizNetworkUp tells whether the network of the Perfiz containers exists, which it
+// does from perfiz start until perfiz stop. A runtime that can not be reached has no
+// network up, and why it could not be reached is logged.
+func isPerfizNetworkUp() bool {
+	_, err := getDockerClient().InspectNetwork(constants.PERFIZ_NETWORK)
+	if err != nil && !dockerapi.IsNotFound(err) {
+		log.Println("Unable to inspect " + constants.PERFIZ_NETWORK + ": " + err.Error())
+	}
+	return err == nil
+}
+
+// getCompose is the compose of the container runtime chosen with --compose or
+// PERFIZ_COMPOSE, or else the one installed.
+func getCompose() env.Compose {
+	choice := composeChoice
+	if choice == "" {
+		choice = os.Getenv(constants.COMPOSE_ENV_VARIABLE)
+	}
+	compose, err := env.DetectCompose(choice, getRuntime().Composes())
+	if err != nil {
+		log.Fatalln(err.Error())
+	}
+	log.Println("Using " + compose.String())
+	return compose
+}
+
+// confirm asks a yes or no question on the terminal. Anything but yes, including no
+// answer at all when stdin is not a terminal, is a no.
+func confirm(question string) bool {
+	fmt.Print(question + " [y/N] ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
 }
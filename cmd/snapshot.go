@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/znsio/perfiz-cli/common/constants"
+	"github.com/znsio/perfiz-cli/common/path"
+	"github.com/znsio/perfiz-cli/common/snapshot"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+var snapshotRestoreYes bool
+
+func init() {
+	cmdSnapshotRestore.Flags().BoolVarP(&snapshotRestoreYes, "yes", "y", false, "do not ask for confirmation")
+	cmdSnapshot.AddCommand(cmdSnapshotSave)
+	cmdSnapshot.AddCommand(cmdSnapshotRestore)
+	cmdSnapshot.AddCommand(cmdSnapshotList)
+	rootCmd.AddCommand(cmdSnapshot)
+}
+
+var cmdSnapshot = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Save and restore Perfiz data",
+	Long: `Save the Grafana, InfluxDB, Prometheus and Gatling data in <your project folder>/perfiz/*_data as a snapshot in ` + constants.SNAPSHOTS_DIR + `
+                and restore it later, for example to keep the dashboards and metrics of a release candidate before a reset.`,
+}
+
+var cmdSnapshotSave = &cobra.Command{
+	Use:   "save <name>",
+	Short: "Save Perfiz data as a snapshot",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		checkPerfizStopped("snapshot save")
+		log.Println("Saving snapshot " + args[0] + " to " + snapshot.File(constants.SNAPSHOTS_DIR, args[0]))
+		manifest, err := snapshot.Save(constants.SNAPSHOTS_DIR, args[0], snapshotStores(), constants.PERFIZ_CLI_VERSION)
+		if err != nil {
+			log.Fatalln("Error saving snapshot: " + err.Error())
+		}
+		log.Println("Saved " + strings.Join(manifest.StoreNames(), ", ") + " (" + path.HumanSize(manifest.Size()) + ") as snapshot " + manifest.Name)
+	},
+}
+
+var cmdSnapshotRestore = &cobra.Command{
+	Use:   "restore <name>",
+	Short: "Restore Perfiz data from a snapshot",
+	Long:  `Replace the data in <your project folder>/perfiz/*_data of every store in the snapshot with its content in the snapshot.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		checkPerfizStopped("snapshot restore")
+		snapshotFile := snapshot.File(constants.SNAPSHOTS_DIR, args[0])
+		manifest, manifestErr := snapshot.ReadManifest(snapshotFile)
+		if manifestErr != nil {
+			log.Fatalln("Unable to read snapshot " + args[0] + ": " + manifestErr.Error())
+		}
+		if !snapshotRestoreYes && !confirm("Replace "+strings.Join(manifest.StoreNames(), ", ")+" data with snapshot "+manifest.Name+"?") {
+			log.Fatalln("Restore cancelled. Run with --yes to restore without confirmation.")
+		}
+		if _, err := snapshot.Restore(constants.SNAPSHOTS_DIR, args[0], snapshotStores()); err != nil {
+			log.Fatalln("Error restoring snapshot: " + err.Error())
+		}
+		log.Println("Restored " + strings.Join(manifest.StoreNames(), ", ") + " from snapshot " + manifest.Name)
+	},
+}
+
+var cmdSnapshotList = &cobra.Command{
+	Use:   "list",
+	Short: "List snapshots",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		manifests, errs := snapshot.List(constants.SNAPSHOTS_DIR)
+		for _, err := range errs {
+			log.Println("Unable to read snapshot: " + err.Error())
+		}
+		if len(manifests) == 0 {
+			fmt.Println("No snapshots found in " + constants.SNAPSHOTS_DIR + ".")
+			return
+		}
+		table := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(table, "NAME\tCREATED\tSTORES\tSIZE")
+		for _, manifest := range manifests {
+			fmt.Fprintln(table, manifest.Name+"\t"+manifest.Created.Local().Format("2006-01-02 15:04:05")+"\t"+
+				strings.Join(manifest.StoreNames(), ", ")+"\t"+path.HumanSize(manifest.Size()))
+		}
+		table.Flush()
+	},
+}
+
+func snapshotStores() []snapshot.Store {
+	var stores []snapshot.Store
+	for _, store := range dataStores {
+		stores = append(stores, snapshot.Store{Name: store.name, Dir: store.dir})
+	}
+	return stores
+}
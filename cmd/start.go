@@ -3,15 +3,28 @@ package cmd
 import (
 	"fmt"
 	"github.com/spf13/cobra"
+	"github.com/znsio/perfiz-cli/common/archive"
 	"github.com/znsio/perfiz-cli/common/constants"
+	"github.com/znsio/perfiz-cli/common/dockerapi"
 	env "github.com/znsio/perfiz-cli/common/environment"
+	"github.com/znsio/perfiz-cli/common/health"
+	"github.com/znsio/perfiz-cli/common/path"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
+var startWait bool
+var startWaitTimeout time.Duration
+
 func init() {
+	cmdStart.Flags().BoolVar(&startWait, "wait", false, "wait until Grafana, Prometheus and InfluxDB are ready")
+	cmdStart.Flags().DurationVar(&startWaitTimeout, "wait-timeout", 2*time.Minute, "how long to wait with --wait before giving up")
 	rootCmd.AddCommand(cmdStart)
 }
 
@@ -23,13 +36,24 @@ var cmdStart = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		log.Println("Starting Perfiz...")
 		perfizHome := env.GetEnvVariable(constants.PERFIZ_HOME_ENV_VARIABLE)
-		env.CheckIfCommandExists("docker", constants.DOCKER_MAJOR_VERSION, constants.DOCKER_MINOR_VERSION)
-		env.CheckIfCommandExists("docker-compose", constants.DOCKER_COMPOSE_MAJOR_VERSION, constants.DOCKER_COMPOSE_MINOR_VERSION)
+		compose := getCompose()
 
 		createDockerEnvFile(perfizHome)
+		if getDockerClient().IsRemote() {
+			workingDir, _ := os.Getwd()
+			for _, configDir := range monitoringConfigDirs {
+				if !path.IsDir(configDir) {
+					exitWithCode(constants.EXIT_CODE_CONFIG_ERROR, configDir+" not found. Run 'init' command to create it.")
+				}
+			}
+			log.Println("Shipping " + strings.Join(monitoringConfigDirs, " and ") + " to " + workingDir + " on " + getDockerClient().HostName())
+			if err := shipMonitoringConfig(workingDir); err != nil {
+				exitWithCode(constants.EXIT_CODE_INFRASTRUCTURE_ERROR, "Error shipping Perfiz configuration to "+getDockerClient().Host()+": "+err.Error())
+			}
+		}
 
 		log.Println("Starting Perfiz Docker Containers...")
-		dockerComposeUp := exec.Command("docker-compose", "--file", perfizHome+"/docker-compose.yml", "--env-file", perfizHome+constants.DOCKER_COMPOSE_ENV_FILE, "up", "-d")
+		dockerComposeUp := dockerCompose(compose, perfizHome, "up", "-d")
 		log.Println("Docker Compose Command: ")
 		log.Println(dockerComposeUp)
 		dockerComposeUpOutput, dockerComposeUpError := dockerComposeUp.CombinedOutput()
@@ -38,10 +62,81 @@ var cmdStart = &cobra.Command{
 			log.Fatalln(dockerComposeUpError.Error())
 		}
 		log.Println(string(dockerComposeUpOutput))
-		log.Println("Navigate to http://localhost:3000 for Grafana")
+		if startWait {
+			waitUntilReady(startWaitTimeout)
+		}
+		log.Println("Navigate to http://" + getDockerClient().HostName() + ":3000 for Grafana")
 	},
 }
 
+// monitoringConfigDirs are the folders of the project the Monitoring Stack bind mounts
+// its configuration from.
+var monitoringConfigDirs = []string{constants.GRAFANA_DASHBOARDS_DIRECTORY, constants.PROMETHEUS_CONFIG_DIR}
+
+// shipMonitoringConfig copies the configuration of the Monitoring Stack to the same path
+// on a remote Docker host, where its containers bind mount it from. The copy goes into
+// a container that bind mounts those paths and is never started.
+func shipMonitoringConfig(workingDir string) error {
+	client := getDockerClient()
+	containerConfig := &dockerapi.ContainerConfig{Image: constants.MAVEN_IMAGE, Cmd: []string{"true"}}
+	for _, configDir := range monitoringConfigDirs {
+		name := filepath.ToSlash(filepath.Clean(configDir))
+		containerConfig.HostConfig.Binds = append(containerConfig.HostConfig.Binds, workingDir+"/"+name+":/perfiz-config/"+name)
+	}
+	containerId, createErr := client.CreateContainer("", containerConfig)
+	if createErr != nil {
+		return createErr
+	}
+	defer client.RemoveContainer(containerId)
+	reader, writer := io.Pipe()
+	go func() {
+		archiveWriter := archive.NewWriter(writer)
+		var err error
+		for _, configDir := range monitoringConfigDirs {
+			if err == nil {
+				err = archiveWriter.AddPathAs(configDir, "perfiz-config/"+filepath.ToSlash(filepath.Clean(configDir)), nil)
+			}
+		}
+		if closeErr := archiveWriter.Close(); err == nil {
+			err = closeErr
+		}
+		writer.CloseWithError(err)
+	}()
+	err := client.CopyToContainer(containerId, "/", reader)
+	reader.Close()
+	return err
+}
+
+// waitUntilReady exits naming the services that did not come up in time.
+func waitUntilReady(timeout time.Duration) {
+	log.Println("Waiting up to " + timeout.String() + " for Grafana, Prometheus and InfluxDB to be ready...")
+	poller := health.NewPoller(timeout)
+	poller.OnReady = func(check health.Check, after time.Duration) {
+		log.Println(check.Service + " is ready after " + after.Round(time.Second).String())
+	}
+	hostName := getDockerClient().HostName()
+	err := poller.WaitFor([]health.Check{
+		{Service: "grafana", Url: onHost(constants.GRAFANA_HEALTH_URL, hostName)},
+		{Service: "prometheus", Url: onHost(constants.PROMETHEUS_READY_URL, hostName)},
+		{Service: "influxdb", Url: onHost(constants.INFLUXDB_PING_URL, hostName)},
+	})
+	if err != nil {
+		exitWithCode(constants.EXIT_CODE_INFRASTRUCTURE_ERROR, "Perfiz did not start. Services "+err.Error()+". Run 'logs' command to see why.")
+	}
+}
+
+// onHost points a localhost url at the machine the Perfiz containers run on.
+func onHost(localhostUrl string, hostName string) string {
+	return strings.Replace(localhostUrl, "localhost", hostName, 1)
+}
+
+// dockerCompose runs compose on the Perfiz docker-compose.yml and the .env file written
+// by start.
+func dockerCompose(compose env.Compose, perfizHome string, args ...string) *exec.Cmd {
+	composeArgs := []string{"--file", perfizHome + "/docker-compose.yml", "--env-file", perfizHome + constants.DOCKER_COMPOSE_ENV_FILE}
+	return compose.Command(append(composeArgs, args...)...)
+}
+
 func createDockerEnvFile(perfizHome string) {
 	uid, gid := env.GetUserIdAndGroupId()
 	workingDir, _ := os.Getwd()
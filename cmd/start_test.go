@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/znsio/perfiz-cli/common/dockerapi"
+)
+
+func Test_shipMonitoringConfig_CopiesConfigToProjectPathOnHost(t *testing.T) {
+	workingDir, _ := os.Getwd()
+	projectDir := t.TempDir()
+	assert.Nil(t, os.Chdir(projectDir))
+	defer os.Chdir(workingDir)
+	assert.Nil(t, os.MkdirAll("perfiz/dashboards", 0755))
+	assert.Nil(t, ioutil.WriteFile("perfiz/dashboards/gatling.json", []byte("{}"), 0644))
+	assert.Nil(t, os.MkdirAll("perfiz/prometheus", 0755))
+	assert.Nil(t, ioutil.WriteFile("perfiz/prometheus/prometheus.yml", []byte("scrape_configs: []"), 0644))
+
+	var shipped []string
+	removed := false
+	useFakeDockerAPI(t, func(writer http.ResponseWriter, request *http.Request) {
+		switch request.Method + " " + request.URL.Path {
+		case "POST /" + dockerapi.API_VERSION + "/containers/create":
+			body, _ := ioutil.ReadAll(request.Body)
+			assert.Contains(t, string(body), projectDir+"/perfiz/dashboards:/perfiz-config/perfiz/dashboards")
+			assert.Contains(t, string(body), projectDir+"/perfiz/prometheus:/perfiz-config/perfiz/prometheus")
+			writer.Write([]byte(`{"Id":"c0ff1e"}`))
+		case "PUT /" + dockerapi.API_VERSION + "/containers/c0ff1e/archive":
+			gzipReader, gzipErr := gzip.NewReader(request.Body)
+			assert.Nil(t, gzipErr)
+			tarReader := tar.NewReader(gzipReader)
+			for {
+				header, err := tarReader.Next()
+				if err == io.EOF {
+					break
+				}
+				assert.Nil(t, err)
+				shipped = append(shipped, header.Name)
+			}
+		case "DELETE /" + dockerapi.API_VERSION + "/containers/c0ff1e":
+			removed = true
+			writer.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", request.Method, request.URL.Path)
+		}
+	})
+
+	assert.Nil(t, shipMonitoringConfig(projectDir))
+	assert.Contains(t, shipped, filepath.ToSlash("perfiz-config/perfiz/dashboards/gatling.json"))
+	assert.Contains(t, shipped, filepath.ToSlash("perfiz-config/perfiz/prometheus/prometheus.yml"))
+	assert.True(t, removed)
+}
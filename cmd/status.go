@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/znsio/perfiz-cli/common/constants"
+	env "github.com/znsio/perfiz-cli/common/environment"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+)
+
+func init() {
+	rootCmd.AddCommand(cmdStatus)
+}
+
+var cmdStatus = &cobra.Command{
+	Use:   "status",
+	Short: "Show status of Perfiz Containers",
+	Long: `Show whether ` + constants.PERFIZ_NETWORK + ` exists, which Perfiz containers are running, healthy or exited with their ports, uptime and images,
+                and which ` + constants.GATLING_CONTAINER + ` test containers are running.
+                Exits with ` + strconv.Itoa(constants.EXIT_CODE_INFRASTRUCTURE_ERROR) + ` when Perfiz is not up or one of its containers is not running or unhealthy.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !isPerfizNetworkUp() {
+			fmt.Println("Network " + constants.PERFIZ_NETWORK + ": missing. Run 'start' command to start Perfiz.")
+			os.Exit(constants.EXIT_CODE_INFRASTRUCTURE_ERROR)
+		}
+		fmt.Println("Network " + constants.PERFIZ_NETWORK + ": up")
+
+		containers, listErr := env.ListContainers(getRuntime().Create("ps", "--all", "--filter", "network="+constants.PERFIZ_NETWORK, "--format", env.PS_FORMAT))
+		if listErr != nil {
+			log.Fatalln("Unable to list Perfiz containers: " + listErr.Error())
+		}
+		healthy := true
+		var testContainers []string
+		var stackContainers []env.Container
+		for _, container := range containers {
+			if isTestContainer(container.Name) {
+				if container.State == "running" {
+					testContainers = append(testContainers, container.Name)
+				}
+				continue
+			}
+			stackContainers = append(stackContainers, container)
+			if container.State != "running" || container.Health() == "unhealthy" {
+				healthy = false
+			}
+		}
+
+		fmt.Println()
+		table := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(table, "CONTAINER\tSTATE\tUPTIME\tHEALTH\tSTATUS\tPORTS\tIMAGE")
+		for _, container := range stackContainers {
+			health := container.Health()
+			if health == "" {
+				health = "-"
+			}
+			uptime := container.Uptime()
+			if uptime == "" {
+				uptime = "-"
+			}
+			fmt.Fprintln(table, container.Name+"\t"+container.State+"\t"+uptime+"\t"+health+"\t"+container.Status+"\t"+container.Ports+"\t"+container.Image)
+		}
+		table.Flush()
+		fmt.Println()
+
+		if len(testContainers) == 0 {
+			fmt.Println("Test containers: none running")
+		}
+		for _, testContainer := range testContainers {
+			fmt.Println("Test container " + testContainer + ": running")
+		}
+		if len(stackContainers) == 0 || !healthy {
+			os.Exit(constants.EXIT_CODE_INFRASTRUCTURE_ERROR)
+		}
+	},
+}
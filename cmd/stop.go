@@ -5,7 +5,6 @@ import (
 	"github.com/znsio/perfiz-cli/common/constants"
 	"log"
 	"os"
-	"os/exec"
 )
 
 func init() {
@@ -25,7 +24,7 @@ var cmdStop = &cobra.Command{
 		} else {
 			log.Println(constants.PERFIZ_HOME_ENV_VARIABLE + ": " + perfizHome)
 		}
-		dockerComposeDown := exec.Command("docker-compose", "--file", perfizHome+"/docker-compose.yml", "--env-file", perfizHome+constants.DOCKER_COMPOSE_ENV_FILE, "down")
+		dockerComposeDown := dockerCompose(getCompose(), perfizHome, "down")
 		log.Println("Docker Compose Command: ")
 		log.Println(dockerComposeDown)
 		dockerComposeDownOutput, dockerComposeDownError := dockerComposeDown.Output()
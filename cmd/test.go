@@ -1,26 +1,51 @@
 package cmd
 
 import (
-	"bufio"
+	"bytes"
 	"errors"
-	"github.com/otiai10/copy"
 	"github.com/spf13/cobra"
+	"github.com/znsio/perfiz-cli/common/archive"
+	"github.com/znsio/perfiz-cli/common/command"
 	"github.com/znsio/perfiz-cli/common/configuration"
 	"github.com/znsio/perfiz-cli/common/constants"
+	"github.com/znsio/perfiz-cli/common/dockerapi"
 	env "github.com/znsio/perfiz-cli/common/environment"
+	"github.com/znsio/perfiz-cli/common/gatling"
+	"github.com/znsio/perfiz-cli/common/history"
 	"github.com/znsio/perfiz-cli/common/path"
-	"gopkg.in/yaml.v2"
+	"github.com/znsio/perfiz-cli/common/report"
+	"github.com/znsio/perfiz-cli/common/retention"
+	"github.com/znsio/perfiz-cli/common/sla"
+	"github.com/znsio/perfiz-cli/common/stream"
+	"github.com/znsio/perfiz-cli/common/version"
+	"github.com/znsio/perfiz-cli/common/workspace"
 	"io"
 	"io/ioutil"
 	"log"
 	"os"
-	"os/exec"
+	"os/signal"
 	"path/filepath"
-	"regexp"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 )
 
+type configOptions struct {
+	profile   string
+	overrides []string
+}
+
+var testConfigOptions configOptions
+var testNoColor bool
+var testSaveContainerLog bool
+var testTags []string
+
 func init() {
+	addConfigFlags(cmdTest, &testConfigOptions)
+	cmdTest.Flags().BoolVar(&testNoColor, "no-color", false, "do not color the output of the test container")
+	cmdTest.Flags().BoolVar(&testSaveContainerLog, "save-container-log", false, "save the complete output of the test container as "+constants.CONTAINER_LOG+" in the run's results directory")
+	cmdTest.Flags().StringArrayVar(&testTags, "tag", nil, "tag the run, for example --tag baseline. Tagged runs are kept by results prune --keep-tagged. Can be repeated")
 	rootCmd.AddCommand(cmdTest)
 }
 
@@ -28,146 +53,587 @@ var cmdTest = &cobra.Command{
 	Use:   "test [perfiz config file name]",
 	Short: "Run Gatling Performance Test",
 	Long:  `Run Gatling Performance Tests as per the configuration in perfiz.yml`,
-	Args: func(cmd *cobra.Command, args []string) error {
-		_, perfizYmlErr := os.Open(constants.DEFAULT_CONFIG_FILE)
-		if len(args) < 1 {
-			if perfizYmlErr != nil {
-				return errors.New("Default Config: " + constants.DEFAULT_CONFIG_FILE + " not found. Please create " + constants.DEFAULT_CONFIG_FILE + " or provide name of config file as argument. Please see https://github.com/znsio/perfiz for instructions and / or run 'init' command and perfiz will add a config file template to help you get started.")
-			} else {
-				return nil
-			}
-		}
-		_, customPerfizYmlErr := os.Open(args[0])
-		if customPerfizYmlErr != nil {
-			return errors.New("Config: " + args[0] + " not found.")
-		}
-		return nil
-	},
+	Args:  configFileArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		workingDir, _ := os.Getwd()
 		perfizHome := env.GetEnvVariable(constants.PERFIZ_HOME_ENV_VARIABLE)
-		env.CheckIfCommandExists("docker", constants.DOCKER_MAJOR_VERSION, constants.DOCKER_MINOR_VERSION)
-		env.CheckIfCommandExists("docker-compose", constants.DOCKER_COMPOSE_MAJOR_VERSION, constants.DOCKER_COMPOSE_MINOR_VERSION)
-
-		var configFile string
-		if len(args) == 1 {
-			configFile = args[0]
-		} else {
-			configFile = constants.DEFAULT_CONFIG_FILE
-		}
+		configFile := getConfigFile(args)
 		log.Println("Perfiz Config File: " + configFile)
-		perfizConfig := &configuration.PerfizConfig{}
-		b, _ := ioutil.ReadFile(configFile)
-		configParseError := yaml.Unmarshal(b, perfizConfig)
-		if configParseError != nil {
-			log.Fatal(configParseError)
+		run := &testRun{
+			configFile: configFile,
+			profile:    testConfigOptions.profile,
+			tags:       testTags,
+			start:      time.Now(),
 		}
+		exitTest := recordingExit(run)
+		perfizDocument, perfizConfig := loadValidConfig(configFile, workingDir, testConfigOptions, exitTest)
+		run.perfizConfig = perfizConfig
+		logTestPlan(perfizConfig)
+		getCompose()
 		karateFeaturesDir := workingDir + "/" + perfizConfig.KarateFeaturesDir
-		if !path.IsDir(karateFeaturesDir) {
-			log.Fatalln("Configuration error in perfiz.yml. karateFeaturesDir: " + perfizConfig.KarateFeaturesDir + ". " + karateFeaturesDir + " is not a directory. Please note that karateFeaturesDir has to be relative to perfiz.yml location.")
-		}
 		gatlingSimulationsDir := configuration.GetGatlingSimulationsDir(workingDir, perfizConfig)
-		if gatlingSimulationsDir != "" && !path.IsDir(gatlingSimulationsDir) {
-			log.Fatalln("Configuration error in perfiz.yml. gatlingSimulationsDir: " + perfizConfig.GatlingSimulationsDir + ". " + gatlingSimulationsDir + " is not a directory. Please note that gatlingSimulationsDir has to be relative to perfiz.yml location.")
-		}
-
-		libRegEx, e := regexp.Compile("^*.scala")
-		if e != nil {
-			log.Fatal(e)
-		}
-
-		filepath.Walk(perfizHome+"/src/test/scala/", func(path string, info os.FileInfo, err error) error {
-			if err == nil && libRegEx.MatchString(info.Name()) && !strings.Contains(info.Name(), "Perfiz") {
-				log.Println("Removing " + info.Name())
-				os.Remove(path)
-			}
-			return nil
-		})
-
-		if gatlingSimulationsDir != "" {
-			log.Println("Copying Gatling Simulations in " + gatlingSimulationsDir)
-			onlyScalaSimulationFiles := copy.Options{
-				Skip: func(src string) (bool, error) {
-					return !path.IsDir(src) && !strings.HasSuffix(src, ".scala"), nil
-				},
-			}
-			copy.Copy(gatlingSimulationsDir, perfizHome+"/src/test/scala", onlyScalaSimulationFiles)
-		}
-
-		_, gatlingConfErr := os.Open(constants.GATLING_CONF_PATH + constants.GATLING_CONF)
-		if gatlingConfErr == nil {
-			log.Println("Copying Gatling Configuration " + constants.GATLING_CONF_PATH + constants.GATLING_CONF)
-			copy.Copy(constants.GATLING_CONF_PATH+constants.GATLING_CONF, perfizHome+"/src/test/resources/"+constants.GATLING_CONF)
-		}
 
 		perfizMavenRepo := perfizHome + "/.m2"
-		if path.IsDir(perfizMavenRepo) {
+		if getDockerClient().IsRemote() {
+			log.Println("Maven dependencies are cached in volume " + constants.MAVEN_REPO_VOLUME + " on " + getDockerClient().HostName() + ".")
+		} else if path.IsDir(perfizMavenRepo) {
 			log.Println(perfizMavenRepo + " available. Skipping Maven Dependency Download.")
 		} else {
 			log.Println(perfizMavenRepo + " does not exist. Maven dependencies will be run downloaded. This may take a while...")
 		}
 
-		dockerNetworkCheck := exec.Command("docker", "network", "inspect", "perfiz-network")
 		log.Println("Running checks...")
-
-		_, dockerNetworkCheckError := dockerNetworkCheck.Output()
-		if dockerNetworkCheckError != nil {
-			log.Fatalln("Error locating docker network perfiz-network. Try running perfiz 'start' command before running 'test'.")
+		if !isPerfizNetworkUp() {
+			exitTest(constants.EXIT_CODE_INFRASTRUCTURE_ERROR, "Error locating docker network perfiz-network. Try running perfiz 'start' command before running 'test'.")
 		}
 
 		log.Println("All checks done.")
 
-		uid, gid := env.GetUserIdAndGroupId()
-
-		dockerCommandArguments := []string{"run", "--rm", "--name", "perfiz-gatling",
-			"-v", perfizMavenRepo + ":/var/maven/.m2",
-			"-v", perfizHome + ":/var/maven",
-			"-v", workingDir + "/" + constants.GATLING_RESULTS_DIR + ":/usr/src/performance-testing/results",
-			"-v", perfizHome + ":/usr/src/performance-testing",
-			"-v", karateFeaturesDir + ":/usr/src/karate-features",
-			"-v", workingDir + "/" + configFile + ":/usr/src/perfiz.yml",
-			"-e", "KARATE_FEATURES=/usr/src/karate-features",
-			"-e", "MAVEN_CONFIG=/var/maven/.m2",
-			"-w", "/usr/src/performance-testing",
-			"--user", uid + ":" + gid,
-			"--network", "perfiz-network",
-			"maven:3.8-jdk-8", "mvn", "clean", "test-compile", "gatling:test", "-DPERFIZ=/usr/src/perfiz.yml", "-Duser.home=/var/maven"}
+		testWorkspace, workspaceErr := createWorkspace(perfizHome, gatlingSimulationsDir)
+		if workspaceErr != nil {
+			exitTest(constants.EXIT_CODE_INFRASTRUCTURE_ERROR, workspaceErr.Error())
+		}
+		if err := writeGeneratedConfig(perfizDocument, testWorkspace); err != nil {
+			removeWorkspace(testWorkspace)
+			exitTest(constants.EXIT_CODE_INFRASTRUCTURE_ERROR, "Error writing resolved config "+testWorkspace.ConfigFile()+": "+err.Error())
+		}
+		remote := getDockerClient().IsRemote()
+		containerConfig := testContainerConfig(perfizHome, testWorkspace, workingDir, karateFeaturesDir, remote)
 
 		karateEnv := perfizConfig.KarateEnv
 		if karateEnv != "" {
 			log.Println("Setting karate.env to " + karateEnv)
-			dockerCommandArguments = append(dockerCommandArguments, "-Dkarate.env="+karateEnv)
+			containerConfig.Cmd = append(containerConfig.Cmd, "-Dkarate.env="+karateEnv)
 		}
 
 		gatlingSimulationClass := perfizConfig.GatlingSimulationClass
 		if gatlingSimulationClass != "" {
 			log.Println("Setting gatling.simulationClass to " + gatlingSimulationClass)
-			dockerCommandArguments = append(dockerCommandArguments, "-Dgatling.simulationClass="+gatlingSimulationClass)
+			containerConfig.Cmd = append(containerConfig.Cmd, "-Dgatling.simulationClass="+gatlingSimulationClass)
 		} else {
 			log.Println("Setting gatling.simulationClass to " + constants.PERFIZ_GATLING_SIMULATION_CLASS)
-			dockerCommandArguments = append(dockerCommandArguments, "-Dgatling.simulationClass="+constants.PERFIZ_GATLING_SIMULATION_CLASS)
+			containerConfig.Cmd = append(containerConfig.Cmd, "-Dgatling.simulationClass="+constants.PERFIZ_GATLING_SIMULATION_CLASS)
+		}
+
+		resultsDir := workingDir + "/" + constants.GATLING_RESULTS_DIR
+		if err := runTestContainer(run, containerConfig, testWorkspace, karateFeaturesDir, resultsDir, remote); err != nil {
+			exitTest(constants.EXIT_CODE_INFRASTRUCTURE_ERROR, "Error starting Gatling Tests: "+err.Error())
+		}
+		exitCode, summary := reportResults(*run)
+		recordHistory(*run, exitCode, summary)
+		if perfizConfig.Retention != nil {
+			applyRetention(perfizConfig.Retention)
+		}
+		if exitCode != 0 {
+			exitWithCode(exitCode, "Gatling Tests failed. Exit code: "+strconv.Itoa(exitCode))
+		}
+		log.Println("Gatling Tests passed.")
+	},
+}
+
+// runTestContainer runs the test container of a run to the end and records how it
+// ended in the run. It returns an error when the container could not be started. The
+// container and the workspace are removed however the run ends, which is why nothing
+// in here exits.
+func runTestContainer(run *testRun, containerConfig *dockerapi.ContainerConfig, testWorkspace *workspace.Workspace, karateFeaturesDir string, resultsDir string, remote bool) error {
+	defer removeWorkspace(testWorkspace)
+	containerName := constants.GATLING_CONTAINER + "-" + testWorkspace.Id()
+	streamer := stream.New(log.Default(), !testNoColor && stream.ColorSupported())
+	var containerLog *os.File
+	if testSaveContainerLog {
+		var createErr error
+		if containerLog, createErr = createContainerLog(resultsDir, containerName); createErr != nil {
+			return errors.New("unable to create container log: " + createErr.Error())
+		}
+		streamer.TeeTo(containerLog)
+	}
+
+	log.Println("Starting Gatling Tests...")
+	log.Println(containerConfig.Image + " " + strings.Join(containerConfig.Cmd, " "))
+	run.start = time.Now()
+	var beforeStart func(containerId string) error
+	if remote {
+		beforeStart = func(containerId string) error {
+			log.Println("Shipping " + testWorkspace.Dir + " and " + karateFeaturesDir + " to " + getDockerClient().Host())
+			return shipProject(containerId, testWorkspace, karateFeaturesDir)
+		}
+	}
+	containerId, startErr := startTestContainer(containerName, containerConfig, beforeStart)
+	if startErr != nil {
+		if containerLog != nil {
+			containerLog.Close()
+			os.Remove(containerLog.Name())
 		}
+		return startErr
+	}
+	defer getDockerClient().RemoveContainer(containerId)
+	log.Println("Gatling Tests container " + containerName + " started.")
+	removeOnInterrupt(containerId)
 
-		dockerRun := exec.Command("docker", dockerCommandArguments...)
-		log.Println("Starting Gatling Tests...")
-		log.Println(dockerRun)
-		dockerRunOutput, _ := dockerRun.StdoutPipe()
-		dockerRunError, _ := dockerRun.StderrPipe()
+	gatlingAssertionsFailed := false
+	streamer.OnLine(func(line string) {
+		if strings.Contains(line, constants.GATLING_ASSERTIONS_FAILED_MESSAGE) {
+			gatlingAssertionsFailed = true
+		}
+	})
+	containerOutput, containerError, logsErr := getDockerClient().ContainerLogs(containerId, true)
+	if logsErr != nil {
+		log.Println("Unable to follow the output of Gatling Tests: " + logsErr.Error())
+	} else {
+		streamer.Consume(
+			stream.Stream{Name: "gatling", Color: stream.COLOR_CYAN, Reader: containerOutput},
+			stream.Stream{Name: "gatling:err", Color: stream.COLOR_RED, Reader: containerError})
+	}
 
-		dockerRun.Start()
+	containerExitCode, oomKilled, waitErr := waitForTestContainer(containerId)
+	run.end = time.Now()
+	if remote {
+		log.Println("Fetching results from " + getDockerClient().Host())
+		if err := fetchResults(containerId, resultsDir); err != nil {
+			log.Println("Error fetching results: " + err.Error())
+		}
+	}
+	if containerLog != nil {
+		containerLog.Close()
+		moveContainerLogToRunDir(containerLog.Name(), resultsDir, run.start)
+	}
+	run.resultsDir = resultsDir
+	run.containerExitCode = containerExitCode
+	run.exitCode = testExitCode(containerExitCode, oomKilled, waitErr, gatlingAssertionsFailed)
+	return nil
+}
 
-		logStreamingOutput(dockerRunOutput)
+// testRun is what the reports of a run of the test container are made from.
+type testRun struct {
+	configFile        string
+	profile           string
+	tags              []string
+	perfizConfig      *configuration.PerfizConfig
+	resultsDir        string
+	start             time.Time
+	end               time.Time
+	containerExitCode int
+	exitCode          int
+}
 
-		logStreamingOutput(dockerRunError)
+// reportResults checks the assertions and writes the reports of the run the test
+// produced. It returns the exit code of the test given how the container exited, and
+// the summary of the run when it left results behind. A container that failed may
+// still have left results behind, which are reported without hiding why it failed.
+func reportResults(run testRun) (int, *report.Summary) {
+	exitCode := run.exitCode
+	testCompleted := exitCode == 0 || exitCode == constants.EXIT_CODE_ASSERTIONS_FAILED
+	runDir, runDirErr := gatling.LatestRunDir(run.resultsDir, run.start)
+	if runDirErr != nil {
+		if !testCompleted {
+			return exitCode, nil
+		}
+		log.Println("Unable to read test results. " + runDirErr.Error())
+		return constants.EXIT_CODE_INFRASTRUCTURE_ERROR, nil
+	}
+	simulationLog, parseErr := gatling.ParseRunDir(runDir)
+	if parseErr != nil {
+		log.Println("Unable to read test results. " + parseErr.Error())
+		if !testCompleted {
+			return exitCode, nil
+		}
+		return constants.EXIT_CODE_INFRASTRUCTURE_ERROR, nil
+	}
+	statistics := gatling.ComputeStatistics(simulationLog)
+	results := sla.Evaluate(run.perfizConfig.Assertions, statistics)
+	if len(results) > 0 {
+		var table bytes.Buffer
+		sla.WriteTable(&table, results)
+		log.Println("Assertions on " + runDir + ":")
+		for _, line := range strings.Split(strings.TrimRight(table.String(), "\n"), "\n") {
+			log.Println(line)
+		}
+		if failures := sla.Failures(results); failures > 0 {
+			log.Println(strconv.Itoa(failures) + " of " + strconv.Itoa(len(results)) + " assertions failed.")
+			if exitCode == 0 {
+				exitCode = constants.EXIT_CODE_ASSERTIONS_FAILED
+			}
+		} else {
+			log.Println("All assertions passed.")
+		}
+	}
+	simulationClass := simulationLog.Run.SimulationClass
+	if simulationClass == "" {
+		simulationClass = run.perfizConfig.GatlingSimulationClass
+	}
 
-		dockerRun.Wait()
-	},
+	junitReport := runDir + "/" + report.JUNIT_REPORT
+	if err := report.WriteJUnit(junitReport, simulationClass, statistics, results); err != nil {
+		log.Println("Error writing JUnit report " + junitReport + ": " + err.Error())
+	} else {
+		log.Println("JUnit report written to " + junitReport)
+	}
+
+	summaryReport := runDir + "/" + report.SUMMARY_REPORT
+	summary := &report.Summary{
+		RunId:             filepath.Base(runDir),
+		ConfigFile:        run.configFile,
+		ResolvedConfig:    run.perfizConfig,
+		KarateEnv:         run.perfizConfig.KarateEnv,
+		SimulationClass:   simulationClass,
+		Start:             run.start,
+		End:               run.end,
+		CliVersion:        constants.PERFIZ_CLI_VERSION,
+		PerfizVersion:     strings.TrimSpace(version.GetPerfizVersion()),
+		ContainerExitCode: run.containerExitCode,
+		ExitCode:          exitCode,
+		Statistics:        statistics,
+		Assertions:        results,
+		Tags:              run.tags,
+	}
+	if err := report.WriteSummary(summaryReport, summary); err != nil {
+		log.Println("Error writing summary " + summaryReport + ": " + err.Error())
+	} else {
+		log.Println("Summary written to " + summaryReport)
+	}
+	return exitCode, summary
+}
+
+func configFileArgs(cmd *cobra.Command, args []string) error {
+	_, perfizYmlErr := os.Open(constants.DEFAULT_CONFIG_FILE)
+	if len(args) < 1 {
+		if perfizYmlErr != nil {
+			return errors.New("Default Config: " + constants.DEFAULT_CONFIG_FILE + " not found. Please create " + constants.DEFAULT_CONFIG_FILE + " or provide name of config file as argument. Please see https://github.com/znsio/perfiz for instructions and / or run 'init' command and perfiz will add a config file template to help you get started.")
+		} else {
+			return nil
+		}
+	}
+	_, customPerfizYmlErr := os.Open(args[0])
+	if customPerfizYmlErr != nil {
+		return errors.New("Config: " + args[0] + " not found.")
+	}
+	return nil
+}
+
+func getConfigFile(args []string) string {
+	if len(args) == 1 {
+		return args[0]
+	}
+	return constants.DEFAULT_CONFIG_FILE
 }
 
-func logStreamingOutput(output io.ReadCloser) {
-	outputScanner := bufio.NewScanner(output)
-	outputScanner.Split(bufio.ScanLines)
-	for outputScanner.Scan() {
-		log.Println(outputScanner.Text())
+func addConfigFlags(cmd *cobra.Command, options *configOptions) {
+	cmd.Flags().StringVar(&options.profile, "profile", "", "name of a profile in the config to apply, for example soak")
+	cmd.Flags().StringArrayVar(&options.overrides, "set", nil, "override a config value, for example --set karateEnv=staging or --set features[0].loadPattern[1].userCount=50. Can be repeated")
+}
+
+// loadValidConfig exits with exit, listing every problem in the config file, when it is
+// not valid. The profile is applied and environment variables are expanded before the
+// overrides are set, so that the command line has the last word.
+func loadValidConfig(configFile string, workingDir string, options configOptions, exit func(code int, v ...interface{})) (*configuration.Document, *configuration.PerfizConfig) {
+	document, documentErr := configuration.ReadDocument(configFile)
+	if documentErr != nil {
+		exit(constants.EXIT_CODE_CONFIG_ERROR, "Configuration error in "+configFile+".\n"+documentErr.Error())
+	}
+	if options.profile != "" {
+		log.Println("Applying profile " + options.profile)
+		if profileErr := document.ApplyProfile(options.profile); profileErr != nil {
+			exit(constants.EXIT_CODE_CONFIG_ERROR, "Configuration error in "+configFile+".\n"+profileErr.Error())
+		}
+	}
+	if interpolationErr := document.Interpolate(os.LookupEnv); interpolationErr != nil {
+		exit(constants.EXIT_CODE_CONFIG_ERROR, "Configuration error in "+configFile+".\n"+interpolationErr.Error())
+	}
+	for _, override := range options.overrides {
+		log.Println("Overriding " + override)
+		if overrideErr := document.Set(override); overrideErr != nil {
+			exit(constants.EXIT_CODE_CONFIG_ERROR, overrideErr)
+		}
+	}
+	problems := configuration.Validate(document, workingDir)
+	if len(problems) > 0 {
+		exit(constants.EXIT_CODE_CONFIG_ERROR, "Configuration error in "+configFile+". "+strconv.Itoa(len(problems))+" problem(s) found.\n"+problems.Error())
+	}
+	perfizConfig, decodeErr := document.Decode()
+	if decodeErr != nil {
+		exit(constants.EXIT_CODE_CONFIG_ERROR, decodeErr)
+	}
+	return document, perfizConfig
+}
+
+// writeGeneratedConfig writes the config as resolved by the CLI into the workspace of
+// the run, which is what the test container gets to see in place of the user's config file.
+func writeGeneratedConfig(document *configuration.Document, testWorkspace *workspace.Workspace) error {
+	generatedConfig, marshalErr := document.Bytes()
+	if marshalErr != nil {
+		return marshalErr
+	}
+	log.Println("Writing resolved config to " + testWorkspace.ConfigFile())
+	return ioutil.WriteFile(testWorkspace.ConfigFile(), generatedConfig, 0600)
+}
+
+func logTestPlan(perfizConfig *configuration.PerfizConfig) {
+	if !perfizConfig.UsesPerfizSimulation() {
+		log.Println("Custom Gatling Simulation " + perfizConfig.GatlingSimulationClass + " will decide the load to be generated.")
+		return
+	}
+	for _, feature := range perfizConfig.Features {
+		log.Println("Feature " + feature.KarateFile + " as Gatling Simulation " + feature.GatlingSimulationName + ":")
+		for _, loadPattern := range feature.LoadPattern {
+			log.Println("  " + loadPattern.String())
+		}
+		if totalDuration, err := feature.TotalDuration(); err == nil {
+			log.Println("  Load is injected for " + totalDuration.String())
+		}
+	}
+}
+
+// createWorkspace stages the run in a workspace of its own, laying the simulations and
+// Gatling configuration of the project over a copy of PERFIZ_HOME.
+func createWorkspace(perfizHome string, gatlingSimulationsDir string) (*workspace.Workspace, error) {
+	testWorkspace, createErr := workspace.Create(perfizHome)
+	if createErr != nil {
+		return nil, errors.New("Error creating workspace from " + perfizHome + ": " + createErr.Error())
+	}
+	log.Println("Staging test in workspace " + testWorkspace.Dir)
+	if gatlingSimulationsDir != "" {
+		log.Println("Copying Gatling Simulations in " + gatlingSimulationsDir)
+		if err := testWorkspace.AddSimulations(gatlingSimulationsDir); err != nil {
+			removeWorkspace(testWorkspace)
+			return nil, errors.New("Error copying Gatling Simulations: " + err.Error())
+		}
+	}
+	gatlingConf := constants.GATLING_CONF_PATH + constants.GATLING_CONF
+	if _, statErr := os.Stat(gatlingConf); statErr == nil {
+		log.Println("Copying Gatling Configuration " + gatlingConf)
+		if err := testWorkspace.AddGatlingConf(gatlingConf); err != nil {
+			removeWorkspace(testWorkspace)
+			return nil, errors.New("Error copying Gatling Configuration: " + err.Error())
+		}
+	}
+	return testWorkspace, nil
+}
+
+func removeWorkspace(testWorkspace *workspace.Workspace) {
+	if err := testWorkspace.Remove(); err != nil {
+		log.Println("Error removing workspace " + testWorkspace.Dir + ": " + err.Error())
+	}
+}
+
+// testContainerConfig is the test container with the workspace, the Maven repository of
+// PERFIZ_HOME and the project mounted into it. A remote Docker host does not share the
+// filesystem, so there the container only gets a volume to cache Maven dependencies in,
+// and shipProject copies the rest.
+func testContainerConfig(perfizHome string, testWorkspace *workspace.Workspace, workingDir string, karateFeaturesDir string, remote bool) *dockerapi.ContainerConfig {
+	containerConfig := &dockerapi.ContainerConfig{
+		Image:      constants.MAVEN_IMAGE,
+		Cmd:        []string{"mvn", "clean", "test-compile", "gatling:test", "-DPERFIZ=/usr/src/perfiz.yml", "-Duser.home=/var/maven"},
+		Env:        []string{"KARATE_FEATURES=/usr/src/karate-features", "MAVEN_CONFIG=/var/maven/.m2"},
+		WorkingDir: "/usr/src/performance-testing",
+		Labels:     map[string]string{constants.PROJECT_LABEL: workingDir},
+		HostConfig: dockerapi.HostConfig{NetworkMode: constants.PERFIZ_NETWORK},
+	}
+	if remote {
+		containerConfig.HostConfig.Binds = []string{constants.MAVEN_REPO_VOLUME + ":/var/maven/.m2"}
+		return containerConfig
+	}
+	uid, gid := env.GetUserIdAndGroupId()
+	containerConfig.User = uid + ":" + gid
+	containerConfig.HostConfig.UsernsMode = getRuntime().UsernsMode()
+	containerConfig.HostConfig.Binds = []string{
+		perfizHome + "/.m2:/var/maven/.m2",
+		testWorkspace.Dir + ":/var/maven",
+		workingDir + "/" + constants.GATLING_RESULTS_DIR + ":/usr/src/performance-testing/results",
+		testWorkspace.Dir + ":/usr/src/performance-testing",
+		karateFeaturesDir + ":/usr/src/karate-features",
+		testWorkspace.ConfigFile() + ":/usr/src/perfiz.yml",
+	}
+	return containerConfig
+}
+
+// startTestContainer creates and starts the test container, as docker run would, and
+// returns its id. The name is that of the run, so that runs of several projects do not
+// clash. beforeStart, when given, prepares the created container.
+func startTestContainer(containerName string, containerConfig *dockerapi.ContainerConfig, beforeStart func(containerId string) error) (string, error) {
+	client := getDockerClient()
+	containerId, createErr := client.CreateContainer(containerName, containerConfig)
+	if createErr != nil {
+		return "", createErr
+	}
+	if beforeStart != nil {
+		if err := beforeStart(containerId); err != nil {
+			client.RemoveContainer(containerId)
+			return "", err
+		}
+	}
+	if startErr := client.StartContainer(containerId); startErr != nil {
+		client.RemoveContainer(containerId)
+		return "", startErr
+	}
+	return containerId, nil
+}
+
+// waitForTestContainer waits for the test container to exit. It returns the exit code
+// of the container, or -1 when it could not be waited for, and whether it was killed
+// for running out of memory.
+func waitForTestContainer(containerId string) (int, bool, error) {
+	client := getDockerClient()
+	containerExitCode, waitErr := client.WaitContainer(containerId)
+	if waitErr != nil {
+		return -1, false, waitErr
+	}
+	state, inspectErr := client.InspectContainer(containerId)
+	return containerExitCode, inspectErr == nil && state.OOMKilled, nil
+}
+
+// shipProject copies into the created test container what it would otherwise have
+// mounted: the workspace, the karate features and the resolved config.
+func shipProject(containerId string, testWorkspace *workspace.Workspace, karateFeaturesDir string) error {
+	reader, writer := io.Pipe()
+	go func() {
+		archiveWriter := archive.NewWriter(writer)
+		err := archiveWriter.AddPathAs(testWorkspace.Dir, "usr/src/performance-testing", nil)
+		if err == nil {
+			err = archiveWriter.AddPathAs(karateFeaturesDir, "usr/src/karate-features", nil)
+		}
+		if err == nil {
+			err = archiveWriter.AddPathAs(testWorkspace.ConfigFile(), "usr/src/perfiz.yml", nil)
+		}
+		if closeErr := archiveWriter.Close(); err == nil {
+			err = closeErr
+		}
+		writer.CloseWithError(err)
+	}()
+	err := getDockerClient().CopyToContainer(containerId, "/", reader)
+	reader.Close()
+	return err
+}
+
+// fetchResults copies the results the test container wrote into the results folder,
+// creating the folders of a project that has not had results yet.
+func fetchResults(containerId string, resultsDir string) error {
+	if err := os.MkdirAll(filepath.Dir(resultsDir), 0755); err != nil {
+		return err
+	}
+	results, err := getDockerClient().CopyFromContainer(containerId, "/usr/src/performance-testing/results")
+	if err != nil {
+		return err
+	}
+	defer results.Close()
+	return archive.ExtractTar(results, filepath.Dir(resultsDir))
+}
+
+// removeOnInterrupt removes the test container when perfiz is interrupted, which ends
+// the test as it would have ended a docker run. Interrupting again exits at once.
+func removeOnInterrupt(containerId string) {
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-interrupts
+		signal.Stop(interrupts)
+		log.Println("Interrupted. Removing Gatling Tests container...")
+		getDockerClient().RemoveContainer(containerId)
+	}()
+}
+
+// testExitCode maps how the test container ended to one of the documented exit codes,
+// telling apart a broken test setup from a test that ran and found the service wanting.
+func testExitCode(containerExitCode int, oomKilled bool, waitErr error, gatlingAssertionsFailed bool) int {
+	if waitErr != nil {
+		log.Println("Error waiting for Gatling Tests: " + waitErr.Error())
+		return constants.EXIT_CODE_INFRASTRUCTURE_ERROR
+	}
+	switch {
+	case containerExitCode == 0:
+		return 0
+	case oomKilled:
+		log.Println("Gatling Tests container was killed for running out of memory.")
+		return constants.EXIT_CODE_CONTAINER_KILLED
+	case containerExitCode == constants.CONTAINER_KILLED_EXIT_CODE:
+		log.Println("Gatling Tests container was killed, possibly for running out of memory.")
+		return constants.EXIT_CODE_CONTAINER_KILLED
+	case containerExitCode == constants.CONTAINER_COMMAND_NOT_EXECUTABLE_EXIT_CODE || containerExitCode == constants.CONTAINER_COMMAND_NOT_FOUND_EXIT_CODE:
+		log.Println("The Gatling Tests container could not run mvn. Exit code: " + strconv.Itoa(containerExitCode))
+		return constants.EXIT_CODE_INFRASTRUCTURE_ERROR
+	case gatlingAssertionsFailed:
+		return constants.EXIT_CODE_ASSERTIONS_FAILED
+	default:
+		return constants.EXIT_CODE_TEST_FAILED
+	}
+}
+
+// recordingExit is exitWithCode for a run that ends before its test container does,
+// which records the run in the history file before exiting.
+func recordingExit(run *testRun) func(code int, v ...interface{}) {
+	return func(code int, v ...interface{}) {
+		run.end = time.Now()
+		recordHistory(*run, code, nil)
+		exitWithCode(code, v...)
+	}
+}
+
+// recordHistory adds the run to the history file, tying its results to the config
+// and git commit that produced them. A run whose config was not valid has no config
+// hash.
+func recordHistory(run testRun, exitCode int, summary *report.Summary) {
+	entry := history.Entry{
+		Start:      run.start,
+		End:        run.end,
+		ConfigFile: run.configFile,
+		Profile:    run.profile,
+		Tags:       run.tags,
+		GitCommit:  env.GetGitCommit(command.Create("git", "rev-parse", "HEAD")),
+		ExitCode:   exitCode,
+		Outcome:    history.Outcome(exitCode),
+	}
+	if run.perfizConfig != nil {
+		entry.ConfigHash = history.ConfigHash(run.perfizConfig)
+		entry.KarateEnv = run.perfizConfig.KarateEnv
+	}
+	if summary != nil {
+		entry.RunId = summary.RunId
+		entry.Requests = summary.Statistics.Global.Count
+		entry.ErrorRate = summary.Statistics.Global.ErrorRate()
+		entry.MeanResponseTime = summary.Statistics.Global.MeanResponseTime
+		entry.P95ResponseTime = summary.Statistics.Global.P95ResponseTime
+		entry.Throughput = summary.Statistics.Global.Throughput
+	}
+	if err := history.Append(constants.HISTORY_FILE, entry); err != nil {
+		log.Println("Error recording run in " + constants.HISTORY_FILE + ": " + err.Error())
+	}
+}
+
+// applyRetention prunes the results folder as configured in perfiz.yml.
+func applyRetention(configRetention *configuration.Retention) {
+	policy, policyErr := retention.PolicyFrom(configRetention)
+	if policyErr != nil {
+		log.Println("Skipping retention: " + policyErr.Error())
+		return
+	}
+	log.Println("Applying retention to " + constants.GATLING_RESULTS_DIR)
+	if err := pruneResults(policy, false); err != nil {
+		log.Println("Error applying retention: " + err.Error())
+	}
+}
+
+// createContainerLog creates a log file in the results folder, named after the test
+// container, as the run directory Gatling writes to does not exist until the
+// simulation has run.
+func createContainerLog(resultsDir string, containerName string) (*os.File, error) {
+	os.MkdirAll(resultsDir, 0755)
+	containerLogFile := resultsDir + "/" + containerName + ".log"
+	containerLog, err := os.Create(containerLogFile)
+	if err != nil {
+		return nil, err
+	}
+	log.Println("Saving Gatling Tests container log to " + containerLogFile)
+	return containerLog, nil
+}
+
+func moveContainerLogToRunDir(containerLogFile string, resultsDir string, testStart time.Time) {
+	runDir, runDirErr := gatling.LatestRunDir(resultsDir, testStart)
+	if runDirErr != nil {
+		log.Println("Gatling Tests container log saved to " + containerLogFile)
+		return
+	}
+	if err := os.Rename(containerLogFile, runDir+"/"+constants.CONTAINER_LOG); err != nil {
+		log.Println("Error moving container log to " + runDir + ": " + err.Error())
+		return
 	}
+	log.Println("Gatling Tests container log saved to " + runDir + "/" + constants.CONTAINER_LOG)
 }
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"archive/tar"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/znsio/perfiz-cli/common/constants"
+	"github.com/znsio/perfiz-cli/common/dockerapi"
+)
+
+// useFakeDockerAPI makes getDockerClient return a client of a handler serving the API.
+func useFakeDockerAPI(t *testing.T, handler http.HandlerFunc) {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client, clientErr := dockerapi.NewClient("tcp://"+server.Listener.Addr().String(), nil)
+	assert.Nil(t, clientErr)
+	dockerClient = client
+	t.Cleanup(func() { dockerClient = nil })
+}
+
+func Test_fetchResults_CopiesResultsIntoProjectWithoutGatlingData(t *testing.T) {
+	useFakeDockerAPI(t, func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "/"+dockerapi.API_VERSION+"/containers/4f2a/archive", request.URL.Path)
+		assert.Equal(t, "/usr/src/performance-testing/results", request.URL.Query().Get("path"))
+		tarWriter := tar.NewWriter(writer)
+		tarWriter.WriteHeader(&tar.Header{Name: "results/", Mode: 0755, Typeflag: tar.TypeDir})
+		tarWriter.WriteHeader(&tar.Header{Name: "results/perfizsimulation-20210801130000000/simulation.log", Mode: 0644, Size: 3, Typeflag: tar.TypeReg})
+		tarWriter.Write([]byte("RUN"))
+		tarWriter.Close()
+	})
+
+	resultsDir := filepath.Join(t.TempDir(), constants.GATLING_RESULTS_DIR)
+	assert.Nil(t, fetchResults("4f2a", resultsDir))
+	assert.FileExists(t, filepath.Join(resultsDir, "perfizsimulation-20210801130000000/simulation.log"))
+}
+
+func Test_createWorkspace_ReturnsErrorForMissingPerfizHome(t *testing.T) {
+	perfizHome := filepath.Join(t.TempDir(), "missing")
+	testWorkspace, err := createWorkspace(perfizHome, "")
+	assert.Nil(t, testWorkspace)
+	assert.Contains(t, err.Error(), "Error creating workspace from "+perfizHome)
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"log"
+	"os"
+)
+
+var validateConfigOptions configOptions
+
+func init() {
+	addConfigFlags(cmdValidate, &validateConfigOptions)
+	rootCmd.AddCommand(cmdValidate)
+}
+
+var cmdValidate = &cobra.Command{
+	Use:   "validate [perfiz config file name]",
+	Short: "Validate Perfiz Config",
+	Long: `Check perfiz.yml for unknown keys, missing karate feature files, unknown load pattern types
+                and malformed user counts or durations. All problems are reported at once with their file:line:column.`,
+	Args: configFileArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		workingDir, _ := os.Getwd()
+		configFile := getConfigFile(args)
+		loadValidConfig(configFile, workingDir, validateConfigOptions, exitWithCode)
+		log.Println(configFile + " is valid.")
+	},
+}
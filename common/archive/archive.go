@@ -0,0 +1,327 @@
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Writer writes a tar.gz archive.
+type Writer struct {
+	file       *os.File
+	gzipWriter *gzip.Writer
+	tarWriter  *tar.Writer
+}
+
+func Create(archiveFile string) (*Writer, error) {
+	file, err := os.Create(archiveFile)
+	if err != nil {
+		return nil, err
+	}
+	writer := NewWriter(file)
+	writer.file = file
+	return writer, nil
+}
+
+// NewWriter writes an archive to a stream, such as the body of a request. Closing the
+// Writer does not close the stream.
+func NewWriter(stream io.Writer) *Writer {
+	gzipWriter := gzip.NewWriter(stream)
+	return &Writer{gzipWriter: gzipWriter, tarWriter: tar.NewWriter(gzipWriter)}
+}
+
+// AddPath adds a file, or a directory with everything in it, under its path as given.
+func (writer *Writer) AddPath(pathFile string) error {
+	return writer.AddPathAs(pathFile, filepath.ToSlash(filepath.Clean(pathFile)), nil)
+}
+
+// AddPathAs adds a file, or a directory with everything in it, under name. Files and
+// directories whose path relative to pathFile is excluded are left out.
+func (writer *Writer) AddPathAs(pathFile string, name string, exclude func(relative string) bool) error {
+	return filepath.Walk(pathFile, func(walkedPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relative, relErr := filepath.Rel(pathFile, walkedPath)
+		if relErr != nil {
+			return relErr
+		}
+		if relative != "." && exclude != nil && exclude(filepath.ToSlash(relative)) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(walkedPath); err != nil {
+				return err
+			}
+		}
+		header, headerErr := tar.FileInfoHeader(info, link)
+		if headerErr != nil {
+			return headerErr
+		}
+		header.Name = name
+		if relative != "." {
+			header.Name = name + "/" + filepath.ToSlash(relative)
+		}
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := writer.tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		file, openErr := os.Open(walkedPath)
+		if openErr != nil {
+			return openErr
+		}
+		defer file.Close()
+		_, copyErr := io.Copy(writer.tarWriter, file)
+		return copyErr
+	})
+}
+
+// AddBytes adds a file with the given content, such as a manifest.
+func (writer *Writer) AddBytes(name string, content []byte) error {
+	header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+	if err := writer.tarWriter.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := writer.tarWriter.Write(content)
+	return err
+}
+
+func (writer *Writer) Close() error {
+	tarErr := writer.tarWriter.Close()
+	gzipErr := writer.gzipWriter.Close()
+	var fileErr error
+	if writer.file != nil {
+		fileErr = writer.file.Close()
+	}
+	for _, err := range []error{tarErr, gzipErr, fileErr} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateFrom archives the given paths into a new archive file.
+func CreateFrom(archiveFile string, paths ...string) error {
+	writer, createErr := Create(archiveFile)
+	if createErr != nil {
+		return createErr
+	}
+	for _, pathFile := range paths {
+		if err := writer.AddPath(pathFile); err != nil {
+			writer.Close()
+			os.Remove(archiveFile)
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// Extract unpacks an archive into a directory. Entries that would end up outside of it
+// are refused.
+func Extract(archiveFile string, destinationDir string) error {
+	return ExtractMatching(archiveFile, destinationDir, func(string) bool { return true })
+}
+
+// ExtractMatching unpacks the entries of an archive whose names are included into a
+// directory, which is created when it does not exist.
+func ExtractMatching(archiveFile string, destinationDir string, include func(name string) bool) error {
+	if err := os.MkdirAll(destinationDir, 0755); err != nil {
+		return err
+	}
+	var dirs []*tar.Header
+	err := walk(archiveFile, func(header *tar.Header, reader io.Reader) error {
+		if !include(header.Name) {
+			return nil
+		}
+		if header.Typeflag == tar.TypeDir && !header.ModTime.IsZero() {
+			dirs = append(dirs, header)
+		}
+		return extractEntry(header, reader, destinationDir)
+	})
+	if err != nil {
+		return err
+	}
+	return restoreDirTimes(dirs, destinationDir)
+}
+
+// ExtractTar unpacks an uncompressed tar stream, such as the Docker Engine API copies
+// out of a container, into a directory, which is created when it does not exist.
+func ExtractTar(stream io.Reader, destinationDir string) error {
+	if err := os.MkdirAll(destinationDir, 0755); err != nil {
+		return err
+	}
+	var dirs []*tar.Header
+	err := walkTar(tar.NewReader(stream), "tar stream", func(header *tar.Header, reader io.Reader) error {
+		if header.Typeflag == tar.TypeDir && !header.ModTime.IsZero() {
+			dirs = append(dirs, header)
+		}
+		return extractEntry(header, reader, destinationDir)
+	})
+	if err != nil {
+		return err
+	}
+	return restoreDirTimes(dirs, destinationDir)
+}
+
+// restoreDirTimes sets the modification times of extracted directories once everything
+// in them has been extracted, which would otherwise have changed them again.
+func restoreDirTimes(dirs []*tar.Header, destinationDir string) error {
+	for i := len(dirs) - 1; i >= 0; i-- {
+		target := filepath.Join(destinationDir, filepath.FromSlash(dirs[i].Name))
+		if err := os.Chtimes(target, dirs[i].ModTime, dirs[i].ModTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractEntry refuses entries that would end up outside of the destination, whether
+// by their name or by a symlink extracted before them, and symlinks that point outside
+// of it.
+func extractEntry(header *tar.Header, reader io.Reader, destinationDir string) error {
+	target := filepath.Join(destinationDir, filepath.FromSlash(header.Name))
+	if !isWithin(destinationDir, target) {
+		return errors.New("archive entry " + header.Name + " is outside of " + destinationDir)
+	}
+	if withinErr := checkResolvedWithin(destinationDir, filepath.Dir(target)); withinErr != nil {
+		return errors.New("archive entry " + header.Name + " is outside of " + destinationDir + ": " + withinErr.Error())
+	}
+	switch header.Typeflag {
+	case tar.TypeDir:
+		return os.MkdirAll(target, os.FileMode(header.Mode)|0700)
+	case tar.TypeSymlink:
+		linkTarget := filepath.FromSlash(header.Linkname)
+		if !filepath.IsAbs(linkTarget) {
+			linkTarget = filepath.Join(filepath.Dir(target), linkTarget)
+		}
+		if !isWithin(destinationDir, linkTarget) {
+			return errors.New("archive entry " + header.Name + " links to " + header.Linkname + ", outside of " + destinationDir)
+		}
+		os.MkdirAll(filepath.Dir(target), 0755)
+		removeNonDir(target)
+		return os.Symlink(header.Linkname, target)
+	case tar.TypeReg:
+		os.MkdirAll(filepath.Dir(target), 0755)
+		// a symlink in place of the file would be written through
+		removeNonDir(target)
+		file, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(header.Mode))
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(file, reader); err != nil {
+			file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil || header.ModTime.IsZero() {
+			return err
+		}
+		return os.Chtimes(target, header.ModTime, header.ModTime)
+	}
+	return nil
+}
+
+// ReadFile returns the content of a single file in an archive.
+func ReadFile(archiveFile string, name string) ([]byte, error) {
+	var content []byte
+	found := false
+	err := walk(archiveFile, func(header *tar.Header, reader io.Reader) error {
+		if found || header.Name != name {
+			return nil
+		}
+		found = true
+		var readErr error
+		content, readErr = ioutil.ReadAll(reader)
+		return readErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, errors.New(name + " not found in " + archiveFile)
+	}
+	return content, nil
+}
+
+func walk(archiveFile string, visit func(header *tar.Header, reader io.Reader) error) error {
+	file, openErr := os.Open(archiveFile)
+	if openErr != nil {
+		return openErr
+	}
+	defer file.Close()
+	gzipReader, gzipErr := gzip.NewReader(file)
+	if gzipErr != nil {
+		return errors.New(archiveFile + ": " + gzipErr.Error())
+	}
+	defer gzipReader.Close()
+	return walkTar(tar.NewReader(gzipReader), archiveFile, visit)
+}
+
+func walkTar(tarReader *tar.Reader, archiveName string, visit func(header *tar.Header, reader io.Reader) error) error {
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.New(archiveName + ": " + err.Error())
+		}
+		if err := visit(header, tarReader); err != nil {
+			return err
+		}
+	}
+}
+
+// checkResolvedWithin checks that dir, with the symlinks of the part of it that exists
+// resolved, is within destinationDir.
+func checkResolvedWithin(destinationDir string, dir string) error {
+	resolvedDestinationDir, err := filepath.EvalSymlinks(destinationDir)
+	if err != nil {
+		return err
+	}
+	existing := dir
+	var missing []string
+	for {
+		resolved, err := filepath.EvalSymlinks(existing)
+		if err == nil {
+			if !isWithin(resolvedDestinationDir, filepath.Join(append([]string{resolved}, missing...)...)) {
+				return errors.New(existing + " resolves to " + resolved)
+			}
+			return nil
+		}
+		if !os.IsNotExist(err) {
+			return err
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return nil
+		}
+		missing = append([]string{filepath.Base(existing)}, missing...)
+		existing = parent
+	}
+}
+
+func removeNonDir(target string) {
+	if info, err := os.Lstat(target); err == nil && !info.IsDir() {
+		os.Remove(target)
+	}
+}
+
+func isWithin(dir string, target string) bool {
+	relative, err := filepath.Rel(dir, target)
+	return err == nil && relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,173 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func inDir(t *testing.T, dir string) {
+	workingDir, _ := os.Getwd()
+	assert.Nil(t, os.Chdir(dir))
+	t.Cleanup(func() { os.Chdir(workingDir) })
+}
+
+func Test_CreateFrom_ArchivesPathsThatExtractRestores(t *testing.T) {
+	projectDir := t.TempDir()
+	inDir(t, projectDir)
+	assert.Nil(t, os.MkdirAll("perfiz/grafana_data/plugins", 0755))
+	assert.Nil(t, ioutil.WriteFile("perfiz/grafana_data/grafana.db", []byte("dashboards"), 0644))
+	archiveFile := filepath.Join(t.TempDir(), "data.tar.gz")
+
+	assert.Nil(t, CreateFrom(archiveFile, "./perfiz/grafana_data"))
+
+	restoreDir := t.TempDir()
+	assert.Nil(t, Extract(archiveFile, restoreDir))
+	content, err := ioutil.ReadFile(filepath.Join(restoreDir, "perfiz/grafana_data/grafana.db"))
+	assert.Nil(t, err)
+	assert.Equal(t, "dashboards", string(content))
+	assert.DirExists(t, filepath.Join(restoreDir, "perfiz/grafana_data/plugins"))
+}
+
+func Test_ReadFile_ReturnsContentOfFileAddedAsBytes(t *testing.T) {
+	archiveFile := filepath.Join(t.TempDir(), "data.tar.gz")
+	writer, err := Create(archiveFile)
+	assert.Nil(t, err)
+	assert.Nil(t, writer.AddBytes("manifest.json", []byte(`{"name": "rc1"}`)))
+	assert.Nil(t, writer.Close())
+
+	content, err := ReadFile(archiveFile, "manifest.json")
+	assert.Nil(t, err)
+	assert.Equal(t, `{"name": "rc1"}`, string(content))
+	_, err = ReadFile(archiveFile, "missing.json")
+	assert.Equal(t, "missing.json not found in "+archiveFile, err.Error())
+}
+
+func Test_Extract_RefusesEntriesOutsideOfDestination(t *testing.T) {
+	archiveFile := filepath.Join(t.TempDir(), "evil.tar.gz")
+	file, _ := os.Create(archiveFile)
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+	tarWriter.WriteHeader(&tar.Header{Name: "../escaped", Mode: 0644, Typeflag: tar.TypeReg})
+	tarWriter.Close()
+	gzipWriter.Close()
+	file.Close()
+
+	destinationDir := t.TempDir()
+	err := Extract(archiveFile, destinationDir)
+	assert.Equal(t, "archive entry ../escaped is outside of "+destinationDir, err.Error())
+}
+
+func Test_AddPathAs_ArchivesDirectoryUnderNameWithoutExcludedPaths(t *testing.T) {
+	perfizHome := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(perfizHome, ".m2/repository"), 0755))
+	assert.Nil(t, os.MkdirAll(filepath.Join(perfizHome, "src/test/scala"), 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(perfizHome, "pom.xml"), []byte("<project/>"), 0644))
+	archiveFile := filepath.Join(t.TempDir(), "workspace.tar.gz")
+	file, _ := os.Create(archiveFile)
+	writer := NewWriter(file)
+
+	assert.Nil(t, writer.AddPathAs(perfizHome, "usr/src/performance-testing", func(relative string) bool {
+		return relative == ".m2"
+	}))
+	assert.Nil(t, writer.Close())
+	assert.Nil(t, file.Close())
+
+	extractDir := t.TempDir()
+	assert.Nil(t, Extract(archiveFile, extractDir))
+	content, err := ioutil.ReadFile(filepath.Join(extractDir, "usr/src/performance-testing/pom.xml"))
+	assert.Nil(t, err)
+	assert.Equal(t, "<project/>", string(content))
+	assert.DirExists(t, filepath.Join(extractDir, "usr/src/performance-testing/src/test/scala"))
+	assert.NoDirExists(t, filepath.Join(extractDir, "usr/src/performance-testing/.m2"))
+}
+
+func Test_ExtractTar_UnpacksUncompressedStream(t *testing.T) {
+	var stream bytes.Buffer
+	tarWriter := tar.NewWriter(&stream)
+	tarWriter.WriteHeader(&tar.Header{Name: "results/", Mode: 0755, Typeflag: tar.TypeDir})
+	tarWriter.WriteHeader(&tar.Header{Name: "results/perfizsimulation-20210801130000/simulation.log", Mode: 0644, Size: 3, Typeflag: tar.TypeReg})
+	tarWriter.Write([]byte("RUN"))
+	tarWriter.Close()
+
+	destinationDir := t.TempDir()
+	assert.Nil(t, ExtractTar(&stream, destinationDir))
+	content, err := ioutil.ReadFile(filepath.Join(destinationDir, "results/perfizsimulation-20210801130000/simulation.log"))
+	assert.Nil(t, err)
+	assert.Equal(t, "RUN", string(content))
+}
+
+func Test_ExtractTar_RestoresModificationTimes(t *testing.T) {
+	modTime := time.Date(2021, 8, 1, 13, 0, 0, 0, time.UTC)
+	var stream bytes.Buffer
+	tarWriter := tar.NewWriter(&stream)
+	tarWriter.WriteHeader(&tar.Header{Name: "results/perfizsimulation-20210801130000/", Mode: 0755, Typeflag: tar.TypeDir, ModTime: modTime})
+	tarWriter.WriteHeader(&tar.Header{Name: "results/perfizsimulation-20210801130000/simulation.log", Mode: 0644, Size: 3, Typeflag: tar.TypeReg, ModTime: modTime})
+	tarWriter.Write([]byte("RUN"))
+	tarWriter.Close()
+
+	destinationDir := t.TempDir()
+	assert.Nil(t, ExtractTar(&stream, destinationDir))
+	for _, name := range []string{"results/perfizsimulation-20210801130000", "results/perfizsimulation-20210801130000/simulation.log"} {
+		info, err := os.Stat(filepath.Join(destinationDir, name))
+		assert.Nil(t, err)
+		assert.True(t, modTime.Equal(info.ModTime()), name)
+	}
+}
+
+func writeTar(entries ...*tar.Header) *bytes.Buffer {
+	var stream bytes.Buffer
+	tarWriter := tar.NewWriter(&stream)
+	for _, entry := range entries {
+		tarWriter.WriteHeader(entry)
+		tarWriter.Write(make([]byte, entry.Size))
+	}
+	tarWriter.Close()
+	return &stream
+}
+
+func Test_ExtractTar_RefusesEntriesThroughSymlinksOutsideOfDestination(t *testing.T) {
+	outsideDir := t.TempDir()
+	destinationDir := t.TempDir()
+	// a symlink extracted by an earlier, lenient version
+	assert.Nil(t, os.Symlink(outsideDir, filepath.Join(destinationDir, "a")))
+
+	err := ExtractTar(writeTar(&tar.Header{Name: "a/passwd", Mode: 0644, Size: 4, Typeflag: tar.TypeReg}), destinationDir)
+	assert.Contains(t, err.Error(), "archive entry a/passwd is outside of "+destinationDir)
+	assert.NoFileExists(t, filepath.Join(outsideDir, "passwd"))
+}
+
+func Test_ExtractTar_RefusesSymlinksOutsideOfDestination(t *testing.T) {
+	destinationDir := t.TempDir()
+	err := ExtractTar(writeTar(
+		&tar.Header{Name: "a", Linkname: "/etc", Typeflag: tar.TypeSymlink},
+		&tar.Header{Name: "a/passwd", Mode: 0644, Size: 4, Typeflag: tar.TypeReg}), destinationDir)
+	assert.Equal(t, "archive entry a links to /etc, outside of "+destinationDir, err.Error())
+
+	err = ExtractTar(writeTar(&tar.Header{Name: "results/b", Linkname: "../../escaped", Typeflag: tar.TypeSymlink}), destinationDir)
+	assert.Equal(t, "archive entry results/b links to ../../escaped, outside of "+destinationDir, err.Error())
+}
+
+func Test_ExtractTar_KeepsSymlinksWithinDestination(t *testing.T) {
+	destinationDir := t.TempDir()
+	assert.Nil(t, ExtractTar(writeTar(
+		&tar.Header{Name: "results/", Mode: 0755, Typeflag: tar.TypeDir},
+		&tar.Header{Name: "latest", Linkname: "results", Typeflag: tar.TypeSymlink},
+		&tar.Header{Name: "latest/simulation.log", Mode: 0644, Size: 3, Typeflag: tar.TypeReg}), destinationDir))
+	assert.FileExists(t, filepath.Join(destinationDir, "results/simulation.log"))
+}
+
+func Test_ExtractTar_CreatesMissingDestination(t *testing.T) {
+	destinationDir := filepath.Join(t.TempDir(), "perfiz/gatling_data")
+	assert.Nil(t, ExtractTar(writeTar(
+		&tar.Header{Name: "results/", Mode: 0755, Typeflag: tar.TypeDir},
+		&tar.Header{Name: "results/perfizsimulation-20210801130000/simulation.log", Mode: 0644, Size: 3, Typeflag: tar.TypeReg}), destinationDir))
+	assert.FileExists(t, filepath.Join(destinationDir, "results/perfizsimulation-20210801130000/simulation.log"))
+}
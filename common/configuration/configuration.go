@@ -1,10 +1,137 @@
 package configuration
 
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type LoadPatternType string
+
+// UserCount is a number of users, or users per second for the *PerSec pattern types.
+type UserCount string
+
+// Duration is a Scala duration such as "10 seconds", which is what Gatling expects.
+type Duration string
+
+const (
+	NOTHING_FOR               LoadPatternType = "nothingFor"
+	AT_ONCE_USERS             LoadPatternType = "atOnceUsers"
+	RAMP_USERS                LoadPatternType = "rampUsers"
+	CONSTANT_USERS_PER_SEC    LoadPatternType = "constantUsersPerSec"
+	RAMP_USERS_PER_SEC        LoadPatternType = "rampUsersPerSec"
+	HEAVISIDE_USERS           LoadPatternType = "heavisideUsers"
+	CONSTANT_CONCURRENT_USERS LoadPatternType = "constantConcurrentUsers"
+	RAMP_CONCURRENT_USERS     LoadPatternType = "rampConcurrentUsers"
+)
+
+// AssertionMetric is a response time in milliseconds, the percentage of failed
+// requests or the number of requests per second.
+type AssertionMetric string
+
+const (
+	MIN_RESPONSE_TIME  AssertionMetric = "minResponseTime"
+	MEAN_RESPONSE_TIME AssertionMetric = "meanResponseTime"
+	MAX_RESPONSE_TIME  AssertionMetric = "maxResponseTime"
+	P50_RESPONSE_TIME  AssertionMetric = "p50ResponseTime"
+	P75_RESPONSE_TIME  AssertionMetric = "p75ResponseTime"
+	P95_RESPONSE_TIME  AssertionMetric = "p95ResponseTime"
+	P99_RESPONSE_TIME  AssertionMetric = "p99ResponseTime"
+	ERROR_RATE         AssertionMetric = "errorRate"
+	THROUGHPUT         AssertionMetric = "throughput"
+)
+
+var AssertionMetrics = []AssertionMetric{MIN_RESPONSE_TIME, MEAN_RESPONSE_TIME, MAX_RESPONSE_TIME, P50_RESPONSE_TIME, P75_RESPONSE_TIME, P95_RESPONSE_TIME, P99_RESPONSE_TIME, ERROR_RATE, THROUGHPUT}
+
+// loadPatternFields records which of the LoadPattern fields a pattern type needs and
+// how its user counts are interpreted.
+type loadPatternFields struct {
+	userCount       bool
+	targetUserCount bool
+	duration        bool
+	usersPerSec     bool
+	ramp            bool
+}
+
+var loadPatternTypes = map[LoadPatternType]loadPatternFields{
+	NOTHING_FOR:               {duration: true},
+	AT_ONCE_USERS:             {userCount: true},
+	RAMP_USERS:                {userCount: true, duration: true},
+	CONSTANT_USERS_PER_SEC:    {userCount: true, duration: true, usersPerSec: true},
+	RAMP_USERS_PER_SEC:        {userCount: true, targetUserCount: true, duration: true, usersPerSec: true, ramp: true},
+	HEAVISIDE_USERS:           {userCount: true, duration: true},
+	CONSTANT_CONCURRENT_USERS: {userCount: true, duration: true},
+	RAMP_CONCURRENT_USERS:     {userCount: true, targetUserCount: true, duration: true, ramp: true},
+}
+
 type PerfizConfig struct {
-	KarateFeaturesDir     string `yaml:"karateFeaturesDir"`
-	KarateEnv             string `yaml:"karateEnv"`
-	GatlingSimulationsDir string `yaml:"gatlingSimulationsDir"`
-	GatlingSimulationClass string `yaml:"gatlingSimulationClass"`
+	KarateFeaturesDir      string             `yaml:"karateFeaturesDir" json:"karateFeaturesDir"`
+	KarateEnv              string             `yaml:"karateEnv,omitempty" json:"karateEnv,omitempty"`
+	GatlingSimulationsDir  string             `yaml:"gatlingSimulationsDir,omitempty" json:"gatlingSimulationsDir,omitempty"`
+	GatlingSimulationClass string             `yaml:"gatlingSimulationClass,omitempty" json:"gatlingSimulationClass,omitempty"`
+	Features               []KarateFeature    `yaml:"features,omitempty" json:"features,omitempty"`
+	Assertions             []Assertion        `yaml:"assertions,omitempty" json:"assertions,omitempty"`
+	Retention              *Retention         `yaml:"retention,omitempty" json:"retention,omitempty"`
+	Profiles               map[string]Profile `yaml:"profiles,omitempty" json:"-"`
+}
+
+// Profile holds the keys of PerfizConfig that a named profile, like smoke or soak, can
+// override. See Document.ApplyProfile for how a profile is merged over the config.
+type Profile struct {
+	KarateFeaturesDir      string          `yaml:"karateFeaturesDir,omitempty"`
+	KarateEnv              string          `yaml:"karateEnv,omitempty"`
+	GatlingSimulationsDir  string          `yaml:"gatlingSimulationsDir,omitempty"`
+	GatlingSimulationClass string          `yaml:"gatlingSimulationClass,omitempty"`
+	Features               []KarateFeature `yaml:"features,omitempty"`
+	Assertions             []Assertion     `yaml:"assertions,omitempty"`
+	Retention              *Retention      `yaml:"retention,omitempty"`
+}
+
+type KarateFeature struct {
+	KarateFile            string        `yaml:"karateFile" json:"karateFile"`
+	GatlingSimulationName string        `yaml:"gatlingSimulationName" json:"gatlingSimulationName"`
+	LoadPattern           []LoadPattern `yaml:"loadPattern" json:"loadPattern"`
+	UriPatterns           []string      `yaml:"uriPatterns,omitempty" json:"uriPatterns,omitempty"`
+}
+
+// LoadPattern is a single Gatling injection step. Counts and durations are kept as
+// written in perfiz.yml, since that is what Perfiz passes on to Gatling, and are
+// parsed on demand.
+type LoadPattern struct {
+	PatternType     LoadPatternType `yaml:"patternType" json:"patternType"`
+	UserCount       UserCount       `yaml:"userCount,omitempty" json:"userCount,omitempty"`
+	TargetUserCount UserCount       `yaml:"targetUserCount,omitempty" json:"targetUserCount,omitempty"`
+	Duration        Duration        `yaml:"duration,omitempty" json:"duration,omitempty"`
+}
+
+// Assertion is a service level check the CLI makes on the results of a test run. It
+// applies to all requests together unless Request names one of them.
+type Assertion struct {
+	Metric      AssertionMetric `yaml:"metric" json:"metric"`
+	Request     string          `yaml:"request,omitempty" json:"request,omitempty"`
+	LessThan    *float64        `yaml:"lessThan,omitempty" json:"lessThan,omitempty"`
+	GreaterThan *float64        `yaml:"greaterThan,omitempty" json:"greaterThan,omitempty"`
+}
+
+// Retention decides which runs in the results folder are pruned after a test run. A
+// run is pruned only when it is neither one of the KeepLast most recent runs nor newer
+// than OlderThan, and, with KeepTagged, has no tags.
+type Retention struct {
+	KeepLast   int      `yaml:"keepLast,omitempty" json:"keepLast,omitempty"`
+	OlderThan  Duration `yaml:"olderThan,omitempty" json:"olderThan,omitempty"`
+	KeepTagged bool     `yaml:"keepTagged,omitempty" json:"keepTagged,omitempty"`
+}
+
+func LoadPatternTypes() []LoadPatternType {
+	var patternTypes []LoadPatternType
+	for patternType := range loadPatternTypes {
+		patternTypes = append(patternTypes, patternType)
+	}
+	sort.Slice(patternTypes, func(i, j int) bool { return patternTypes[i] < patternTypes[j] })
+	return patternTypes
 }
 
 func GetGatlingSimulationsDir(workingDir string, config *PerfizConfig) string {
@@ -13,3 +140,116 @@ func GetGatlingSimulationsDir(workingDir string, config *PerfizConfig) string {
 	}
 	return workingDir + "/" + config.GatlingSimulationsDir
 }
+
+func (config *PerfizConfig) UsesPerfizSimulation() bool {
+	return config.GatlingSimulationClass == ""
+}
+
+// TotalDuration adds up the durations of all load patterns of the feature, which is
+// how long Gatling keeps injecting users for it.
+func (feature *KarateFeature) TotalDuration() (time.Duration, error) {
+	var total time.Duration
+	for _, loadPattern := range feature.LoadPattern {
+		if loadPattern.Duration == "" {
+			continue
+		}
+		duration, err := loadPattern.ParsedDuration()
+		if err != nil {
+			return 0, err
+		}
+		total += duration
+	}
+	return total, nil
+}
+
+func (metric AssertionMetric) IsKnown() bool {
+	for _, knownMetric := range AssertionMetrics {
+		if metric == knownMetric {
+			return true
+		}
+	}
+	return false
+}
+
+func (assertion Assertion) String() string {
+	request := assertion.Request
+	if request == "" {
+		request = "all requests"
+	}
+	description := string(assertion.Metric) + " of " + request
+	if assertion.GreaterThan != nil {
+		description += " > " + strconv.FormatFloat(*assertion.GreaterThan, 'f', -1, 64)
+	}
+	if assertion.LessThan != nil {
+		description += " < " + strconv.FormatFloat(*assertion.LessThan, 'f', -1, 64)
+	}
+	return description
+}
+
+func (loadPattern *LoadPattern) ParsedUserCount() (float64, error) {
+	return ParseUserCount(string(loadPattern.UserCount))
+}
+
+func (loadPattern *LoadPattern) ParsedTargetUserCount() (float64, error) {
+	return ParseUserCount(string(loadPattern.TargetUserCount))
+}
+
+func (loadPattern *LoadPattern) ParsedDuration() (time.Duration, error) {
+	return ParseDuration(string(loadPattern.Duration))
+}
+
+func (loadPattern LoadPattern) String() string {
+	description := string(loadPattern.PatternType)
+	if loadPattern.UserCount != "" {
+		description += " " + string(loadPattern.UserCount)
+		if loadPattern.TargetUserCount != "" {
+			description += " to " + string(loadPattern.TargetUserCount)
+		}
+		description += " users"
+	}
+	if loadPattern.Duration != "" {
+		description += " during " + string(loadPattern.Duration)
+	}
+	return description
+}
+
+func ParseUserCount(userCount string) (float64, error) {
+	trimmedUserCount := strings.TrimSpace(userCount)
+	if trimmedUserCount == "" {
+		return 0, errors.New("user count is empty")
+	}
+	parsedUserCount, err := strconv.ParseFloat(trimmedUserCount, 64)
+	if err != nil || math.IsNaN(parsedUserCount) || math.IsInf(parsedUserCount, 0) {
+		return 0, errors.New("user count " + strconv.Quote(userCount) + " is not a number")
+	}
+	return parsedUserCount, nil
+}
+
+var durationUnits = map[string]time.Duration{
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"h": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+	"ms": time.Millisecond, "milli": time.Millisecond, "millis": time.Millisecond, "millisecond": time.Millisecond, "milliseconds": time.Millisecond,
+}
+
+// ParseDuration understands the Scala duration format Perfiz hands to Gatling,
+// for example "10 seconds", "2 minutes" or "500ms".
+func ParseDuration(duration string) (time.Duration, error) {
+	trimmedDuration := strings.TrimSpace(duration)
+	if trimmedDuration == "" {
+		return 0, errors.New("duration is empty")
+	}
+	unitStart := strings.IndexFunc(trimmedDuration, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if unitStart <= 0 {
+		return 0, errors.New("duration " + strconv.Quote(duration) + " must be a number followed by a unit, for example \"10 seconds\"")
+	}
+	length, lengthErr := strconv.ParseFloat(trimmedDuration[:unitStart], 64)
+	unit, knownUnit := durationUnits[strings.ToLower(strings.TrimSpace(trimmedDuration[unitStart:]))]
+	if lengthErr != nil || !knownUnit {
+		return 0, errors.New("duration " + strconv.Quote(duration) + " must be a number followed by a unit, for example \"10 seconds\"")
+	}
+	return time.Duration(length * float64(unit)), nil
+}
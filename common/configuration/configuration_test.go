@@ -0,0 +1,74 @@
+package configuration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const perfizYml = `
+karateFeaturesDir: "karate-features"
+karateEnv: "perf"
+features:
+  - karateFile: "bookings.feature"
+    gatlingSimulationName: "Bookings"
+    loadPattern:
+      - patternType: "nothingFor"
+        duration: "10 seconds"
+      - patternType: "rampUsers"
+        userCount: 10
+        duration: "1 minute"
+      - patternType: "rampUsersPerSec"
+        userCount: "1"
+        targetUserCount: "5"
+        duration: "30 seconds"
+    uriPatterns:
+      - "/bookings/{id}"
+`
+
+func Test_Decode_ReadsFeaturesAndLoadPatterns(t *testing.T) {
+	document, parseErr := ParseDocument("perfiz.yml", []byte(perfizYml))
+	assert.Nil(t, parseErr)
+	perfizConfig, err := document.Decode()
+	assert.Nil(t, err)
+	assert.Equal(t, "karate-features", perfizConfig.KarateFeaturesDir)
+	assert.Equal(t, "perf", perfizConfig.KarateEnv)
+	assert.True(t, perfizConfig.UsesPerfizSimulation())
+	assert.Len(t, perfizConfig.Features, 1)
+	feature := perfizConfig.Features[0]
+	assert.Equal(t, "bookings.feature", feature.KarateFile)
+	assert.Equal(t, "Bookings", feature.GatlingSimulationName)
+	assert.Equal(t, []string{"/bookings/{id}"}, feature.UriPatterns)
+	assert.Equal(t, LoadPattern{PatternType: RAMP_USERS_PER_SEC, UserCount: "1", TargetUserCount: "5", Duration: "30 seconds"}, feature.LoadPattern[2])
+	userCount, _ := feature.LoadPattern[1].ParsedUserCount()
+	assert.Equal(t, float64(10), userCount)
+	totalDuration, _ := feature.TotalDuration()
+	assert.Equal(t, 100*time.Second, totalDuration)
+}
+
+func Test_ParseDuration_UnderstandsScalaDurations(t *testing.T) {
+	for duration, expected := range map[string]time.Duration{
+		"10 seconds": 10 * time.Second,
+		"1 minute":   time.Minute,
+		"500ms":      500 * time.Millisecond,
+		"1.5 hours":  90 * time.Minute,
+		" 2 MINUTES": 2 * time.Minute,
+	} {
+		parsed, err := ParseDuration(duration)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, parsed, duration)
+	}
+}
+
+func Test_ParseDuration_RejectsDurationsWithoutKnownUnits(t *testing.T) {
+	for _, duration := range []string{"", "10", "seconds", "10 fortnights"} {
+		_, err := ParseDuration(duration)
+		assert.NotNil(t, err, duration)
+	}
+}
+
+func Test_LoadPattern_String_DescribesTheInjectionStep(t *testing.T) {
+	assert.Equal(t, "nothingFor during 10 seconds", LoadPattern{PatternType: NOTHING_FOR, Duration: "10 seconds"}.String())
+	assert.Equal(t, "rampUsersPerSec 1 to 5 users during 30 seconds", LoadPattern{PatternType: RAMP_USERS_PER_SEC, UserCount: "1", TargetUserCount: "5", Duration: "30 seconds"}.String())
+}
@@ -0,0 +1,184 @@
+package configuration
+
+import (
+	"errors"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is a perfiz.yml as a YAML node tree. Unlike PerfizConfig it remembers where
+// every value was written so that problems can be reported with file:line:column.
+type Document struct {
+	File    string
+	root    *yaml.Node
+	origins map[*yaml.Node]string
+}
+
+type pathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+var cliOnlyKeys = map[string]bool{PROFILES_KEY: true, "assertions": true, "retention": true}
+
+var yamlErrorLineRegex = regexp.MustCompile(`line (\d+): (.*)`)
+
+func ReadDocument(configFile string) (*Document, error) {
+	configBytes, readErr := ioutil.ReadFile(configFile)
+	if readErr != nil {
+		return nil, readErr
+	}
+	return ParseDocument(configFile, configBytes)
+}
+
+// ParseDocument returns Problems when the content is not valid YAML.
+func ParseDocument(configFile string, configBytes []byte) (*Document, error) {
+	var fileNode yaml.Node
+	if err := yaml.Unmarshal(configBytes, &fileNode); err != nil {
+		return nil, Problems{yamlErrorToProblem(configFile, err.Error())}
+	}
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: 1, Column: 1}
+	if len(fileNode.Content) > 0 {
+		root = fileNode.Content[0]
+	}
+	return &Document{File: configFile, root: root}, nil
+}
+
+func (document *Document) Decode() (*PerfizConfig, error) {
+	perfizConfig := &PerfizConfig{}
+	if err := document.root.Decode(perfizConfig); err != nil {
+		return nil, err
+	}
+	return perfizConfig, nil
+}
+
+// Bytes is the document as handed to the test container, without the keys that only
+// the CLI understands.
+func (document *Document) Bytes() ([]byte, error) {
+	root := *document.root
+	if root.Kind == yaml.MappingNode {
+		root.Content = nil
+		for i := 0; i+1 < len(document.root.Content); i += 2 {
+			if !cliOnlyKeys[document.root.Content[i].Value] {
+				root.Content = append(root.Content, document.root.Content[i], document.root.Content[i+1])
+			}
+		}
+	}
+	return yaml.Marshal(&root)
+}
+
+// Node returns the node at a path such as "features[0].loadPattern[1].userCount".
+// When part of the path does not exist the deepest node that does is returned, so
+// that a missing key can still be pointed at through its parent.
+func (document *Document) Node(path string) *yaml.Node {
+	node := document.root
+	segments, err := parsePath(path)
+	if err != nil {
+		return node
+	}
+	for _, segment := range segments {
+		child := childNode(node, segment)
+		if child == nil {
+			return node
+		}
+		node = child
+	}
+	return node
+}
+
+func (document *Document) Problem(path string, message string) Problem {
+	return document.problemAt(document.Node(path), path, message)
+}
+
+func (document *Document) problemAt(node *yaml.Node, path string, message string) Problem {
+	if origin, found := document.origins[node]; found {
+		return Problem{File: origin, Path: path, Message: message}
+	}
+	return Problem{File: document.File, Line: node.Line, Column: node.Column, Path: path, Message: message}
+}
+
+// markOrigin records that a node and its children were not read from the document's
+// file, so that problems with them point at where they came from instead.
+func (document *Document) markOrigin(node *yaml.Node, origin string) {
+	if document.origins == nil {
+		document.origins = map[*yaml.Node]string{}
+	}
+	document.origins[node] = origin
+	for _, child := range node.Content {
+		document.markOrigin(child, origin)
+	}
+}
+
+func childNode(node *yaml.Node, segment pathSegment) *yaml.Node {
+	if segment.isIndex {
+		if node.Kind != yaml.SequenceNode || segment.index < 0 || segment.index >= len(node.Content) {
+			return nil
+		}
+		return node.Content[segment.index]
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == segment.key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func parsePath(path string) ([]pathSegment, error) {
+	var segments []pathSegment
+	for _, part := range strings.Split(path, ".") {
+		key := part
+		indexes := ""
+		if bracket := strings.Index(part, "["); bracket >= 0 {
+			key = part[:bracket]
+			indexes = part[bracket:]
+		}
+		if key == "" && (indexes == "" || len(segments) == 0) {
+			return nil, errors.New("invalid path " + strconv.Quote(path))
+		}
+		if key != "" {
+			segments = append(segments, pathSegment{key: key})
+		}
+		for indexes != "" {
+			closing := strings.Index(indexes, "]")
+			if !strings.HasPrefix(indexes, "[") || closing < 0 {
+				return nil, errors.New("invalid path " + strconv.Quote(path))
+			}
+			index, err := strconv.Atoi(indexes[1:closing])
+			if err != nil || index < 0 {
+				return nil, errors.New("invalid index in path " + strconv.Quote(path))
+			}
+			segments = append(segments, pathSegment{index: index, isIndex: true})
+			indexes = indexes[closing+1:]
+		}
+	}
+	return segments, nil
+}
+
+func joinPath(parent string, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+func indexPath(parent string, index int) string {
+	return parent + "[" + strconv.Itoa(index) + "]"
+}
+
+func yamlErrorToProblem(configFile string, message string) Problem {
+	message = strings.TrimPrefix(message, "yaml: ")
+	if match := yamlErrorLineRegex.FindStringSubmatch(message); match != nil {
+		line, _ := strconv.Atoi(match[1])
+		return Problem{File: configFile, Line: line, Message: match[2]}
+	}
+	return Problem{File: configFile, Message: message}
+}
@@ -0,0 +1,69 @@
+package configuration
+
+import (
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var variableRegex = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// Interpolate expands ${VAR} and ${VAR:-default} in the values of the document using
+// lookup, usually os.LookupEnv. As in a shell the default is used when the variable is
+// unset or empty, and $$ stands for a literal $. Expansion happens on parsed values
+// rather than on the raw file so that a value containing YAML syntax, like a password
+// with a '#' in it, cannot change the structure of the document.
+// Profiles are left as is, only the one applied to the document gets expanded.
+// Every variable that can not be resolved is reported as a Problem.
+func (document *Document) Interpolate(lookup func(string) (string, bool)) error {
+	var problems Problems
+	if document.root.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(document.root.Content); i += 2 {
+			if document.root.Content[i].Value != PROFILES_KEY {
+				interpolateNode(document, document.root.Content[i+1], document.root.Content[i].Value, lookup, &problems)
+			}
+		}
+	} else {
+		interpolateNode(document, document.root, "", lookup, &problems)
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+	return nil
+}
+
+func interpolateNode(document *Document, node *yaml.Node, nodePath string, lookup func(string) (string, bool), problems *Problems) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			interpolateNode(document, node.Content[i+1], joinPath(nodePath, node.Content[i].Value), lookup, problems)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			interpolateNode(document, item, indexPath(nodePath, i), lookup, problems)
+		}
+	case yaml.ScalarNode:
+		if !strings.Contains(node.Value, "$") {
+			return
+		}
+		node.Value = variableRegex.ReplaceAllStringFunc(node.Value, func(reference string) string {
+			if reference == "$$" {
+				return "$"
+			}
+			match := variableRegex.FindStringSubmatch(reference)
+			if value, found := lookup(match[1]); found && value != "" {
+				return value
+			}
+			if match[2] != "" {
+				return match[3]
+			}
+			*problems = append(*problems, document.problemAt(node, nodePath, "unresolved variable "+match[1]+". Please set it or provide a default with ${"+match[1]+":-default}"))
+			return reference
+		})
+		if node.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) == 0 {
+			// let a plain value like ${USERS} resolve to whatever it expands to, as if written literally
+			node.Tag = ""
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package configuration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func lookupFrom(variables map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, found := variables[name]
+		return value, found
+	}
+}
+
+func Test_Interpolate_ExpandsVariablesAndDefaults(t *testing.T) {
+	document, _ := ParseDocument("perfiz.yml", []byte(`karateFeaturesDir: "karate-features"
+karateEnv: ${KARATE_ENV}
+features:
+  - karateFile: "${FEATURE:-bookings}.feature"
+    gatlingSimulationName: "Price $$5 ${EMPTY:-}"
+    loadPattern:
+      - patternType: "rampUsers"
+        userCount: ${USERS:-10}
+        duration: "${DURATION:-1 minute}"
+`))
+	err := document.Interpolate(lookupFrom(map[string]string{"KARATE_ENV": "staging#1", "USERS": "50", "EMPTY": ""}))
+	assert.Nil(t, err)
+	perfizConfig, _ := document.Decode()
+	assert.Equal(t, "staging#1", perfizConfig.KarateEnv)
+	assert.Equal(t, "bookings.feature", perfizConfig.Features[0].KarateFile)
+	assert.Equal(t, "Price $5 ", perfizConfig.Features[0].GatlingSimulationName)
+	assert.Equal(t, UserCount("50"), perfizConfig.Features[0].LoadPattern[0].UserCount)
+	assert.Equal(t, Duration("1 minute"), perfizConfig.Features[0].LoadPattern[0].Duration)
+	generated, _ := document.Bytes()
+	assert.Contains(t, string(generated), "karateEnv: staging#1\n")
+	assert.Contains(t, string(generated), "userCount: 50\n")
+}
+
+func Test_Interpolate_ListsAllUnresolvedVariables(t *testing.T) {
+	document, _ := ParseDocument("perfiz.yml", []byte(`karateFeaturesDir: ${FEATURES_DIR}
+karateEnv: "${KARATE_ENV}-${REGION}"
+`))
+	err := document.Interpolate(lookupFrom(map[string]string{"REGION": "eu"}))
+	assert.Equal(t, "perfiz.yml:1:20: karateFeaturesDir: unresolved variable FEATURES_DIR. Please set it or provide a default with ${FEATURES_DIR:-default}\n"+
+		"perfiz.yml:2:12: karateEnv: unresolved variable KARATE_ENV. Please set it or provide a default with ${KARATE_ENV:-default}", err.Error())
+}
@@ -0,0 +1,106 @@
+package configuration
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Set overrides a value of the document from a "key.path=value" override, as given to
+// --set on the command line, for example "features[0].loadPattern[1].userCount=50".
+// The path has to exist in PerfizConfig and list indexes have to exist in the document.
+// The value is read as YAML, so "[a, b]" sets a list, and problems with it are
+// reported against the override rather than against the config file.
+func (document *Document) Set(override string) error {
+	separator := strings.Index(override, "=")
+	if separator <= 0 {
+		return errors.New("invalid override " + strconv.Quote(override) + ". Expected key.path=value")
+	}
+	overridePath, value := override[:separator], override[separator+1:]
+	segments, pathErr := parsePath(overridePath)
+	if pathErr != nil {
+		return pathErr
+	}
+	if typeErr := checkPathType(reflect.TypeOf(PerfizConfig{}), segments, overridePath); typeErr != nil {
+		return typeErr
+	}
+	valueNode := overrideValueNode(value)
+	document.markOrigin(valueNode, "--set "+override)
+
+	node := document.root
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		if segment.isIndex {
+			if node.Kind != yaml.SequenceNode || segment.index >= len(node.Content) {
+				return errors.New("invalid override " + strconv.Quote(override) + ". " + segmentsPath(segments[:i+1]) + " does not exist in " + document.File)
+			}
+			if last {
+				node.Content[segment.index] = valueNode
+			}
+			node = node.Content[segment.index]
+			continue
+		}
+		if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null" {
+			*node = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: node.Line, Column: node.Column}
+		}
+		if node.Kind != yaml.MappingNode {
+			return errors.New("invalid override " + strconv.Quote(override) + ". " + segmentsPath(segments[:i]) + " is not a mapping in " + document.File)
+		}
+		child := childNode(node, segment)
+		if last {
+			child = valueNode
+		} else if child == nil {
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			document.markOrigin(child, "--set "+override)
+		}
+		setMappingValue(node, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: segment.key}, child)
+		node = child
+	}
+	return nil
+}
+
+func overrideValueNode(value string) *yaml.Node {
+	var valueDocument yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &valueDocument); err != nil || len(valueDocument.Content) == 0 {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	}
+	return valueDocument.Content[0]
+}
+
+func checkPathType(configType reflect.Type, segments []pathSegment, overridePath string) error {
+	for i, segment := range segments {
+		for configType.Kind() == reflect.Ptr {
+			configType = configType.Elem()
+		}
+		switch {
+		case segment.isIndex && configType.Kind() == reflect.Slice:
+			configType = configType.Elem()
+		case !segment.isIndex && configType.Kind() == reflect.Map:
+			configType = configType.Elem()
+		case !segment.isIndex && configType.Kind() == reflect.Struct:
+			field, found := fieldByYamlName(configType, segment.key)
+			if !found {
+				return errors.New("invalid override path " + strconv.Quote(overridePath) + ". Unknown key " + segment.key + ". Valid keys here are: " + strings.Join(yamlFieldNames(configType), ", "))
+			}
+			configType = field.Type
+		default:
+			return errors.New("invalid override path " + strconv.Quote(overridePath) + ". " + segmentsPath(segments[:i+1]) + " does not exist in Perfiz Config")
+		}
+	}
+	return nil
+}
+
+func segmentsPath(segments []pathSegment) string {
+	segmentPath := ""
+	for _, segment := range segments {
+		if segment.isIndex {
+			segmentPath = indexPath(segmentPath, segment.index)
+		} else {
+			segmentPath = joinPath(segmentPath, segment.key)
+		}
+	}
+	return segmentPath
+}
@@ -0,0 +1,34 @@
+package configuration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Set_OverridesExistingAndMissingValues(t *testing.T) {
+	document, _ := ParseDocument("perfiz.yml", []byte(perfizYml))
+	assert.Nil(t, document.Set("karateEnv=staging"))
+	assert.Nil(t, document.Set("gatlingSimulationsDir=simulations"))
+	assert.Nil(t, document.Set("features[0].loadPattern[1].userCount=50"))
+	assert.Nil(t, document.Set("features[0].uriPatterns=[/a, /b]"))
+	perfizConfig, _ := document.Decode()
+	assert.Equal(t, "staging", perfizConfig.KarateEnv)
+	assert.Equal(t, "simulations", perfizConfig.GatlingSimulationsDir)
+	assert.Equal(t, UserCount("50"), perfizConfig.Features[0].LoadPattern[1].UserCount)
+	assert.Equal(t, []string{"/a", "/b"}, perfizConfig.Features[0].UriPatterns)
+}
+
+func Test_Set_RejectsUnknownKeysAndMissingIndexes(t *testing.T) {
+	document, _ := ParseDocument("perfiz.yml", []byte(perfizYml))
+	assert.Equal(t, "invalid override \"karateEnv\". Expected key.path=value", document.Set("karateEnv").Error())
+	assert.Equal(t, "invalid override path \"features[0].users\". Unknown key users. Valid keys here are: karateFile, gatlingSimulationName, loadPattern, uriPatterns", document.Set("features[0].users=5").Error())
+	assert.Equal(t, "invalid override \"features[1].karateFile=a.feature\". features[1] does not exist in perfiz.yml", document.Set("features[1].karateFile=a.feature").Error())
+}
+
+func Test_Set_ReportsProblemsAgainstTheOverride(t *testing.T) {
+	workingDir := createKarateFeatures(t, "bookings.feature")
+	document, _ := ParseDocument("perfiz.yml", []byte(perfizYml))
+	assert.Nil(t, document.Set("features[0].loadPattern[1].userCount=-5"))
+	assert.Equal(t, []string{"--set features[0].loadPattern[1].userCount=-5: features[0].loadPattern[1].userCount: userCount must be positive"}, problemStrings(Validate(document, workingDir)))
+}
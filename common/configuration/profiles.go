@@ -0,0 +1,75 @@
+package configuration
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const PROFILES_KEY = "profiles"
+
+// ApplyProfile deep-merges the named profile over the rest of the document. Mappings
+// are merged key by key while lists and single values in the profile replace those of
+// the config, so a profile that changes a feature's loadPattern lists the complete
+// loadPattern. Merged values keep their position in the profile for error reporting.
+func (document *Document) ApplyProfile(name string) error {
+	profile := childNode(childNodeOrEmpty(document.root, PROFILES_KEY), pathSegment{key: name})
+	if profile == nil {
+		profileNames := document.ProfileNames()
+		if len(profileNames) == 0 {
+			return errors.New("profile " + name + " not found. " + document.File + " does not declare any profiles.")
+		}
+		return errors.New("profile " + name + " not found in " + document.File + ". Available profiles: " + strings.Join(profileNames, ", "))
+	}
+	if profile.Kind != yaml.MappingNode {
+		return Problems{document.Problem(joinPath(PROFILES_KEY, name), "expected a mapping of keys to values")}
+	}
+	for i := 0; i+1 < len(profile.Content); i += 2 {
+		if profile.Content[i].Value == PROFILES_KEY {
+			continue
+		}
+		setMappingValue(document.root, profile.Content[i], mergeNodes(childNode(document.root, pathSegment{key: profile.Content[i].Value}), profile.Content[i+1]))
+	}
+	return nil
+}
+
+func (document *Document) ProfileNames() []string {
+	var profileNames []string
+	profiles := childNodeOrEmpty(document.root, PROFILES_KEY)
+	for i := 0; i+1 < len(profiles.Content); i += 2 {
+		profileNames = append(profileNames, profiles.Content[i].Value)
+	}
+	sort.Strings(profileNames)
+	return profileNames
+}
+
+func mergeNodes(base *yaml.Node, overlay *yaml.Node) *yaml.Node {
+	if base == nil || base.Kind != yaml.MappingNode || overlay.Kind != yaml.MappingNode {
+		return overlay
+	}
+	merged := *base
+	merged.Content = append([]*yaml.Node{}, base.Content...)
+	for i := 0; i+1 < len(overlay.Content); i += 2 {
+		setMappingValue(&merged, overlay.Content[i], mergeNodes(childNode(&merged, pathSegment{key: overlay.Content[i].Value}), overlay.Content[i+1]))
+	}
+	return &merged
+}
+
+func setMappingValue(mapping *yaml.Node, key *yaml.Node, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key.Value {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, key, value)
+}
+
+func childNodeOrEmpty(node *yaml.Node, key string) *yaml.Node {
+	if child := childNode(node, pathSegment{key: key}); child != nil {
+		return child
+	}
+	return &yaml.Node{Kind: yaml.MappingNode}
+}
@@ -0,0 +1,57 @@
+package configuration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const perfizYmlWithProfiles = `karateFeaturesDir: "karate-features"
+karateEnv: "perf"
+features:
+  - karateFile: "bookings.feature"
+    gatlingSimulationName: "Bookings"
+    loadPattern:
+      - patternType: "rampUsers"
+        userCount: 10
+        duration: "1 minute"
+profiles:
+  soak:
+    karateEnv: "soak"
+    features:
+      - karateFile: "bookings.feature"
+        gatlingSimulationName: "BookingsSoak"
+        loadPattern:
+          - patternType: "constantUsersPerSec"
+            userCount: 5
+            durations: "2 hours"
+  smoke: {}
+`
+
+func Test_ApplyProfile_MergesProfileOverConfig(t *testing.T) {
+	document, _ := ParseDocument("perfiz.yml", []byte(perfizYmlWithProfiles))
+	assert.Nil(t, document.ApplyProfile("soak"))
+	perfizConfig, _ := document.Decode()
+	assert.Equal(t, "karate-features", perfizConfig.KarateFeaturesDir)
+	assert.Equal(t, "soak", perfizConfig.KarateEnv)
+	assert.Equal(t, "BookingsSoak", perfizConfig.Features[0].GatlingSimulationName)
+	assert.Equal(t, []LoadPattern{{PatternType: CONSTANT_USERS_PER_SEC, UserCount: "5"}}, perfizConfig.Features[0].LoadPattern)
+	problems := Validate(document, createKarateFeatures(t, "bookings.feature"))
+	assert.Equal(t, []string{
+		"perfiz.yml:17:13: features[0].loadPattern[0]: duration is required for constantUsersPerSec",
+		"perfiz.yml:19:13: features[0].loadPattern[0].durations: unknown key. Valid keys here are: patternType, userCount, targetUserCount, duration",
+	}, problemStrings(problems))
+}
+
+func Test_ApplyProfile_LeavesProfilesOutOfContainerConfig(t *testing.T) {
+	document, _ := ParseDocument("perfiz.yml", []byte(perfizYmlWithProfiles))
+	assert.Nil(t, document.ApplyProfile("smoke"))
+	generated, _ := document.Bytes()
+	assert.NotContains(t, string(generated), "profiles")
+	assert.Contains(t, string(generated), "karateEnv: \"perf\"")
+}
+
+func Test_ApplyProfile_ListsAvailableProfilesWhenProfileIsMissing(t *testing.T) {
+	document, _ := ParseDocument("perfiz.yml", []byte(perfizYmlWithProfiles))
+	assert.Equal(t, "profile spike not found in perfiz.yml. Available profiles: smoke, soak", document.ApplyProfile("spike").Error())
+}
@@ -0,0 +1,146 @@
+package configuration
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const JSON_SCHEMA_DRAFT = "https://json-schema.org/draft/2020-12/schema"
+
+var schemaDescriptions = map[string]string{
+	"karateFeaturesDir":      "Directory containing the Karate feature files, relative to perfiz.yml.",
+	"karateEnv":              "Value of karate.env for the test run.",
+	"gatlingSimulationsDir":  "Directory containing custom Gatling simulations (*.scala), relative to perfiz.yml.",
+	"gatlingSimulationClass": "Fully qualified name of a custom Gatling simulation to run instead of the Perfiz simulation.",
+	"features":               "Karate features to turn into Gatling scenarios.",
+	"karateFile":             "Karate feature file, relative to karateFeaturesDir.",
+	"gatlingSimulationName":  "Name of the Gatling scenario for this feature.",
+	"loadPattern":            "Gatling injection steps for this feature, applied in order.",
+	"uriPatterns":            "URI patterns used to group requests, for example /bookings/{id}.",
+	"patternType":            "Gatling injection step.",
+	"userCount":              "Number of users, or users per second for the *PerSec pattern types.",
+	"targetUserCount":        "Number of users, or users per second, at the end of a ramp.",
+	"duration":               "Duration of the step, for example \"10 seconds\".",
+	"profiles":               "Named profiles, like smoke or soak, that override the config when selected with --profile.",
+	"assertions":             "Checks on the results of the test run. perfiz test fails when one of them does not hold.",
+	"metric":                 "Response times are in milliseconds, errorRate is the percentage of failed requests and throughput is in requests per second.",
+	"request":                "Name of the request to check, all requests together when left out.",
+	"lessThan":               "The metric has to be below this value.",
+	"greaterThan":            "The metric has to be above this value.",
+	"retention":              "Which runs in perfiz/gatling_data/results to prune after every test run.",
+	"keepLast":               "Number of most recent runs to keep.",
+	"olderThan":              "Prune runs older than this, for example \"30d\".",
+	"keepTagged":             "Keep runs that have been tagged, whatever their age.",
+}
+
+// interpolationPattern matches a value that is an environment variable, which is only
+// known once the config is interpolated.
+const interpolationPattern = `\$\{[A-Za-z_][A-Za-z0-9_]*(:-[^}]*)?\}`
+
+// schemaTypes holds the schemas of the config types that are not plain strings, lists
+// or mappings in perfiz.yml.
+var schemaTypes = map[reflect.Type]func() map[string]interface{}{
+	reflect.TypeOf(LoadPatternType("")): func() map[string]interface{} {
+		var patternTypes []string
+		for _, patternType := range LoadPatternTypes() {
+			patternTypes = append(patternTypes, string(patternType))
+		}
+		return map[string]interface{}{"type": "string", "enum": patternTypes}
+	},
+	reflect.TypeOf(AssertionMetric("")): func() map[string]interface{} {
+		var metrics []string
+		for _, metric := range AssertionMetrics {
+			metrics = append(metrics, string(metric))
+		}
+		return map[string]interface{}{"type": "string", "enum": metrics}
+	},
+	reflect.TypeOf(UserCount("")): func() map[string]interface{} {
+		return map[string]interface{}{
+			"type":    []string{"number", "string"},
+			"pattern": `^(\s*[0-9]+(\.[0-9]+)?\s*|` + interpolationPattern + `)$`,
+			"minimum": 0,
+		}
+	},
+	reflect.TypeOf(Duration("")): func() map[string]interface{} {
+		var units []string
+		for unit := range durationUnits {
+			units = append(units, unit)
+		}
+		sort.Sort(sort.Reverse(sort.StringSlice(units)))
+		for i, unit := range units {
+			units[i] = anyCase(unit)
+		}
+		return map[string]interface{}{
+			"type":    "string",
+			"pattern": `^(\s*[0-9]+(\.[0-9]+)?\s*(` + strings.Join(units, "|") + `)\s*|` + interpolationPattern + `)$`,
+		}
+	},
+}
+
+// anyCase turns a lower case word into a pattern matching it in any case, as units of
+// durations are. JSON Schema patterns have no flag for case insensitive matching.
+func anyCase(word string) string {
+	var pattern strings.Builder
+	for _, letter := range word {
+		pattern.WriteString("[" + string(letter) + strings.ToUpper(string(letter)) + "]")
+	}
+	return pattern.String()
+}
+
+// Schema describes perfiz.yml as a JSON Schema. It is generated from PerfizConfig
+// so that it always matches what the CLI parses.
+func Schema() ([]byte, error) {
+	schema := typeSchema(reflect.TypeOf(PerfizConfig{}))
+	schema["$schema"] = JSON_SCHEMA_DRAFT
+	schema["title"] = "Perfiz Config"
+	schema["description"] = "Configuration of a Perfiz performance test, usually perfiz.yml."
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+func typeSchema(schemaType reflect.Type) map[string]interface{} {
+	if customSchema, found := schemaTypes[schemaType]; found {
+		return customSchema()
+	}
+	switch schemaType.Kind() {
+	case reflect.Ptr:
+		return typeSchema(schemaType.Elem())
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		required := []string{}
+		for i := 0; i < schemaType.NumField(); i++ {
+			field := schemaType.Field(i)
+			name := yamlFieldName(field)
+			if name == "" {
+				continue
+			}
+			property := typeSchema(field.Type)
+			if description, found := schemaDescriptions[name]; found {
+				property["description"] = description
+			}
+			properties[name] = property
+			if !strings.Contains(field.Tag.Get("yaml"), ",omitempty") && field.Type.Kind() != reflect.Ptr {
+				required = append(required, name)
+			}
+		}
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"required":             required,
+			"additionalProperties": false,
+		}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(schemaType.Elem())}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": typeSchema(schemaType.Elem())}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		return map[string]interface{}{"type": "string"}
+	}
+}
@@ -0,0 +1,44 @@
+package configuration
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Schema_IsDerivedFromConfigTypes(t *testing.T) {
+	schemaBytes, err := Schema()
+	assert.Nil(t, err)
+	var schema map[string]interface{}
+	assert.Nil(t, json.Unmarshal(schemaBytes, &schema))
+	assert.Equal(t, JSON_SCHEMA_DRAFT, schema["$schema"])
+	assert.Equal(t, []interface{}{"karateFeaturesDir"}, schema["required"])
+
+	features := schema["properties"].(map[string]interface{})["features"].(map[string]interface{})
+	loadPattern := features["items"].(map[string]interface{})["properties"].(map[string]interface{})["loadPattern"].(map[string]interface{})
+	loadPatternProperties := loadPattern["items"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Len(t, loadPatternProperties["patternType"].(map[string]interface{})["enum"], len(LoadPatternTypes()))
+	assert.Equal(t, []interface{}{"number", "string"}, loadPatternProperties["userCount"].(map[string]interface{})["type"])
+	assert.Equal(t, "string", loadPatternProperties["duration"].(map[string]interface{})["type"])
+}
+
+func Test_Schema_AcceptsInterpolatedUserCountsAndDurations(t *testing.T) {
+	userCountPattern := regexp.MustCompile(schemaTypes[reflect.TypeOf(UserCount(""))]()["pattern"].(string))
+	for _, userCount := range []string{"10", " 2.5 ", "${USERS}", "${USERS:-10}"} {
+		assert.True(t, userCountPattern.MatchString(userCount), userCount)
+	}
+	for _, userCount := range []string{"ten", "${USERS", "$USERS", "10 ${USERS}"} {
+		assert.False(t, userCountPattern.MatchString(userCount), userCount)
+	}
+
+	durationPattern := regexp.MustCompile(schemaTypes[reflect.TypeOf(Duration(""))]()["pattern"].(string))
+	for _, duration := range []string{"30 seconds", " 2 MINUTES", "${DURATION}", "${DURATION:-5 minutes}"} {
+		assert.True(t, durationPattern.MatchString(duration), duration)
+	}
+	for _, duration := range []string{"30", "${1DURATION}"} {
+		assert.False(t, durationPattern.MatchString(duration), duration)
+	}
+}
@@ -0,0 +1,300 @@
+package configuration
+
+import (
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/znsio/perfiz-cli/common/path"
+	"gopkg.in/yaml.v3"
+)
+
+type Problem struct {
+	File    string
+	Line    int
+	Column  int
+	Path    string
+	Message string
+}
+
+type Problems []Problem
+
+func (problem Problem) String() string {
+	position := problem.File
+	if problem.Line > 0 {
+		position += ":" + strconv.Itoa(problem.Line)
+		if problem.Column > 0 {
+			position += ":" + strconv.Itoa(problem.Column)
+		}
+	}
+	if problem.Path != "" {
+		return position + ": " + problem.Path + ": " + problem.Message
+	}
+	return position + ": " + problem.Message
+}
+
+func (problems Problems) Error() string {
+	lines := make([]string, len(problems))
+	for i, problem := range problems {
+		lines[i] = problem.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Validate reports every problem in the document at once, rather than stopping at the
+// first one, so that a perfiz.yml can be fixed in a single pass. Paths in the config
+// are resolved relative to workingDir, as the test command does.
+func Validate(document *Document, workingDir string) Problems {
+	var problems Problems
+	checkStructure(document, document.root, reflect.TypeOf(PerfizConfig{}), "", &problems)
+	perfizConfig := &PerfizConfig{}
+	decodeErr := document.root.Decode(perfizConfig)
+	if decodeErr != nil && len(problems) == 0 {
+		if typeErr, isTypeErr := decodeErr.(*yaml.TypeError); isTypeErr {
+			for _, message := range typeErr.Errors {
+				problems = append(problems, yamlErrorToProblem(document.File, message))
+			}
+		} else {
+			problems = append(problems, yamlErrorToProblem(document.File, decodeErr.Error()))
+		}
+	}
+	problems = append(problems, checkConfig(document, perfizConfig, workingDir)...)
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Line != problems[j].Line {
+			return problems[i].Line < problems[j].Line
+		}
+		return problems[i].Column < problems[j].Column
+	})
+	return withoutDuplicates(problems)
+}
+
+// withoutDuplicates drops problems reported twice for the same value, which happens when
+// a value of the applied profile is seen both in its profile and in the merged config.
+func withoutDuplicates(problems Problems) Problems {
+	var uniqueProblems Problems
+	for _, problem := range problems {
+		duplicate := false
+		for _, uniqueProblem := range uniqueProblems {
+			if uniqueProblem.File == problem.File && uniqueProblem.Line == problem.Line && uniqueProblem.Column == problem.Column && uniqueProblem.Message == problem.Message {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			uniqueProblems = append(uniqueProblems, problem)
+		}
+	}
+	return uniqueProblems
+}
+
+func checkStructure(document *Document, node *yaml.Node, expectedType reflect.Type, nodePath string, problems *Problems) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null" {
+		return
+	}
+	problem := func(message string) {
+		*problems = append(*problems, document.problemAt(node, nodePath, message))
+	}
+	for expectedType.Kind() == reflect.Ptr {
+		expectedType = expectedType.Elem()
+	}
+	switch expectedType.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			problem("expected a mapping of keys to values")
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			field, found := fieldByYamlName(expectedType, keyNode.Value)
+			if !found {
+				*problems = append(*problems, document.problemAt(keyNode, joinPath(nodePath, keyNode.Value), "unknown key. Valid keys here are: "+strings.Join(yamlFieldNames(expectedType), ", ")))
+				continue
+			}
+			checkStructure(document, valueNode, field.Type, joinPath(nodePath, keyNode.Value), problems)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			problem("expected a mapping of keys to values")
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			checkStructure(document, node.Content[i+1], expectedType.Elem(), joinPath(nodePath, node.Content[i].Value), problems)
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			problem("expected a list")
+			return
+		}
+		for i, item := range node.Content {
+			checkStructure(document, item, expectedType.Elem(), indexPath(nodePath, i), problems)
+		}
+	case reflect.String:
+		if node.Kind != yaml.ScalarNode {
+			problem("expected a single value")
+		}
+	default:
+		if node.Kind != yaml.ScalarNode {
+			problem("expected a single value")
+			return
+		}
+		if err := node.Decode(reflect.New(expectedType).Interface()); err != nil {
+			if expectedType.Kind() == reflect.Bool {
+				problem(strconv.Quote(node.Value) + " must be true or false")
+			} else {
+				problem(strconv.Quote(node.Value) + " must be a number")
+			}
+		}
+	}
+}
+
+func fieldByYamlName(structType reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < structType.NumField(); i++ {
+		if yamlFieldName(structType.Field(i)) == name {
+			return structType.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func yamlFieldNames(structType reflect.Type) []string {
+	var names []string
+	for i := 0; i < structType.NumField(); i++ {
+		if name := yamlFieldName(structType.Field(i)); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func yamlFieldName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+func checkConfig(document *Document, perfizConfig *PerfizConfig, workingDir string) Problems {
+	var problems Problems
+	karateFeaturesDir := workingDir + "/" + perfizConfig.KarateFeaturesDir
+	karateFeaturesDirExists := false
+	if perfizConfig.KarateFeaturesDir == "" {
+		problems = append(problems, document.Problem("karateFeaturesDir", "karateFeaturesDir is required"))
+	} else if !path.IsDir(karateFeaturesDir) {
+		problems = append(problems, document.Problem("karateFeaturesDir", karateFeaturesDir+" is not a directory. Please note that karateFeaturesDir has to be relative to perfiz.yml location."))
+	} else {
+		karateFeaturesDirExists = true
+	}
+	gatlingSimulationsDir := GetGatlingSimulationsDir(workingDir, perfizConfig)
+	if gatlingSimulationsDir != "" && !path.IsDir(gatlingSimulationsDir) {
+		problems = append(problems, document.Problem("gatlingSimulationsDir", gatlingSimulationsDir+" is not a directory. Please note that gatlingSimulationsDir has to be relative to perfiz.yml location."))
+	}
+	if perfizConfig.UsesPerfizSimulation() && len(perfizConfig.Features) == 0 {
+		problems = append(problems, document.Problem("features", "at least one feature is required unless gatlingSimulationClass is set"))
+	}
+	for i, feature := range perfizConfig.Features {
+		featurePath := indexPath("features", i)
+		if feature.KarateFile == "" {
+			problems = append(problems, document.Problem(featurePath, "karateFile is required"))
+		} else if karateFeaturesDirExists {
+			if fileInfo, err := os.Stat(karateFeaturesDir + "/" + feature.KarateFile); err != nil || fileInfo.IsDir() {
+				problems = append(problems, document.Problem(joinPath(featurePath, "karateFile"), feature.KarateFile+" not found in karateFeaturesDir "+perfizConfig.KarateFeaturesDir))
+			}
+		}
+		if feature.GatlingSimulationName == "" {
+			problems = append(problems, document.Problem(featurePath, "gatlingSimulationName is required"))
+		}
+		if len(feature.LoadPattern) == 0 {
+			problems = append(problems, document.Problem(featurePath, "at least one loadPattern is required"))
+		}
+		for j, loadPattern := range feature.LoadPattern {
+			problems = append(problems, checkLoadPattern(document, indexPath(joinPath(featurePath, "loadPattern"), j), loadPattern)...)
+		}
+	}
+	for i, assertion := range perfizConfig.Assertions {
+		assertionPath := indexPath("assertions", i)
+		if assertion.Metric == "" {
+			problems = append(problems, document.Problem(assertionPath, "metric is required"))
+		} else if !assertion.Metric.IsKnown() {
+			var metrics []string
+			for _, metric := range AssertionMetrics {
+				metrics = append(metrics, string(metric))
+			}
+			problems = append(problems, document.Problem(joinPath(assertionPath, "metric"), "unknown metric "+strconv.Quote(string(assertion.Metric))+". Known metrics are: "+strings.Join(metrics, ", ")))
+		}
+		if assertion.LessThan == nil && assertion.GreaterThan == nil {
+			problems = append(problems, document.Problem(assertionPath, "lessThan or greaterThan is required"))
+		}
+		if assertion.LessThan != nil && *assertion.LessThan < 0 {
+			problems = append(problems, document.Problem(joinPath(assertionPath, "lessThan"), "lessThan must not be negative"))
+		}
+		if assertion.GreaterThan != nil && *assertion.GreaterThan < 0 {
+			problems = append(problems, document.Problem(joinPath(assertionPath, "greaterThan"), "greaterThan must not be negative"))
+		}
+	}
+	if retention := perfizConfig.Retention; retention != nil {
+		if retention.KeepLast < 0 {
+			problems = append(problems, document.Problem("retention.keepLast", "keepLast must not be negative"))
+		}
+		if retention.OlderThan != "" {
+			if _, err := ParseDuration(string(retention.OlderThan)); err != nil {
+				problems = append(problems, document.Problem("retention.olderThan", err.Error()))
+			}
+		}
+		if retention.KeepLast == 0 && retention.OlderThan == "" {
+			problems = append(problems, document.Problem("retention", "keepLast or olderThan is required"))
+		}
+	}
+	return problems
+}
+
+func checkLoadPattern(document *Document, loadPatternPath string, loadPattern LoadPattern) Problems {
+	var problems Problems
+	if loadPattern.PatternType == "" {
+		return Problems{document.Problem(loadPatternPath, "patternType is required")}
+	}
+	fields, known := loadPatternTypes[loadPattern.PatternType]
+	if !known {
+		var knownTypes []string
+		for _, patternType := range LoadPatternTypes() {
+			knownTypes = append(knownTypes, string(patternType))
+		}
+		return Problems{document.Problem(joinPath(loadPatternPath, "patternType"), "unknown patternType "+strconv.Quote(string(loadPattern.PatternType))+". Known pattern types are: "+strings.Join(knownTypes, ", "))}
+	}
+	checkUsers := func(key string, userCount UserCount) {
+		if userCount == "" {
+			problems = append(problems, document.Problem(loadPatternPath, key+" is required for "+string(loadPattern.PatternType)))
+			return
+		}
+		parsedUserCount, err := ParseUserCount(string(userCount))
+		if err != nil {
+			problems = append(problems, document.Problem(joinPath(loadPatternPath, key), err.Error()))
+		} else if parsedUserCount < 0 || (parsedUserCount == 0 && !fields.ramp) {
+			problems = append(problems, document.Problem(joinPath(loadPatternPath, key), key+" must be positive"))
+		} else if !fields.usersPerSec && parsedUserCount != math.Trunc(parsedUserCount) {
+			problems = append(problems, document.Problem(joinPath(loadPatternPath, key), key+" must be a whole number of users for "+string(loadPattern.PatternType)))
+		}
+	}
+	if fields.userCount {
+		checkUsers("userCount", loadPattern.UserCount)
+	}
+	if fields.targetUserCount {
+		checkUsers("targetUserCount", loadPattern.TargetUserCount)
+	}
+	if fields.duration {
+		if loadPattern.Duration == "" {
+			problems = append(problems, document.Problem(loadPatternPath, "duration is required for "+string(loadPattern.PatternType)))
+		} else if duration, err := loadPattern.ParsedDuration(); err != nil {
+			problems = append(problems, document.Problem(joinPath(loadPatternPath, "duration"), err.Error()))
+		} else if duration <= 0 {
+			problems = append(problems, document.Problem(joinPath(loadPatternPath, "duration"), "duration must be positive"))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,104 @@
+package configuration
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createKarateFeatures(t *testing.T, featureFiles ...string) string {
+	workingDir, err := ioutil.TempDir("", "perfiz-validation")
+	assert.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(workingDir) })
+	assert.Nil(t, os.MkdirAll(workingDir+"/karate-features", 0755))
+	for _, featureFile := range featureFiles {
+		assert.Nil(t, ioutil.WriteFile(workingDir+"/karate-features/"+featureFile, []byte("Feature: test"), 0644))
+	}
+	return workingDir
+}
+
+func validate(t *testing.T, workingDir string, config string) Problems {
+	document, err := ParseDocument("perfiz.yml", []byte(config))
+	assert.Nil(t, err)
+	return Validate(document, workingDir)
+}
+
+func Test_Validate_AcceptsAValidConfig(t *testing.T) {
+	workingDir := createKarateFeatures(t, "bookings.feature")
+	assert.Empty(t, validate(t, workingDir, perfizYml))
+}
+
+func Test_Validate_ReportsAllProblemsWithPositions(t *testing.T) {
+	workingDir := createKarateFeatures(t)
+	problems := validate(t, workingDir, `karateFeaturesDir: "karate-features"
+karateEnvironment: "perf"
+features:
+  - karateFile: "missing.feature"
+    gatlingSimulationName: "Missing"
+    loadPattern:
+      - patternType: "rampUser"
+        duration: "10 seconds"
+      - patternType: "rampUsers"
+        userCount: -1
+        duration: "10 sec onds"
+      - patternType: "atOnceUsers"
+        userCount: 2.5
+      - patternType: "rampUsersPerSec"
+        userCount: 1
+`)
+	assert.Equal(t, []string{
+		"perfiz.yml:2:1: karateEnvironment: unknown key. Valid keys here are: karateFeaturesDir, karateEnv, gatlingSimulationsDir, gatlingSimulationClass, features, assertions, retention, profiles",
+		"perfiz.yml:4:17: features[0].karateFile: missing.feature not found in karateFeaturesDir karate-features",
+		"perfiz.yml:7:22: features[0].loadPattern[0].patternType: unknown patternType \"rampUser\". Known pattern types are: atOnceUsers, constantConcurrentUsers, constantUsersPerSec, heavisideUsers, nothingFor, rampConcurrentUsers, rampUsers, rampUsersPerSec",
+		"perfiz.yml:10:20: features[0].loadPattern[1].userCount: userCount must be positive",
+		"perfiz.yml:11:19: features[0].loadPattern[1].duration: duration \"10 sec onds\" must be a number followed by a unit, for example \"10 seconds\"",
+		"perfiz.yml:13:20: features[0].loadPattern[2].userCount: userCount must be a whole number of users for atOnceUsers",
+		"perfiz.yml:14:9: features[0].loadPattern[3]: targetUserCount is required for rampUsersPerSec",
+		"perfiz.yml:14:9: features[0].loadPattern[3]: duration is required for rampUsersPerSec",
+	}, problemStrings(problems))
+}
+
+func Test_Validate_ReportsStructuralProblems(t *testing.T) {
+	workingDir := createKarateFeatures(t)
+	problems := validate(t, workingDir, `karateFeaturesDir: "karate-features"
+gatlingSimulationClass: "com.example.Simulation"
+features: "bookings.feature"
+`)
+	assert.Equal(t, []string{"perfiz.yml:3:11: features: expected a list"}, problemStrings(problems))
+}
+
+func Test_Validate_ReportsRetentionProblems(t *testing.T) {
+	workingDir := createKarateFeatures(t)
+	problems := validate(t, workingDir, `karateFeaturesDir: "karate-features"
+gatlingSimulationClass: "com.example.Simulation"
+retention:
+  keepLast: -1
+  olderThan: "a month"
+`)
+	assert.Equal(t, []string{
+		"perfiz.yml:4:13: retention.keepLast: keepLast must not be negative",
+		"perfiz.yml:5:14: retention.olderThan: duration \"a month\" must be a number followed by a unit, for example \"10 seconds\"",
+	}, problemStrings(problems))
+
+	problems = validate(t, workingDir, `karateFeaturesDir: "karate-features"
+gatlingSimulationClass: "com.example.Simulation"
+retention:
+  keepTagged: true
+`)
+	assert.Equal(t, []string{"perfiz.yml:4:3: retention: keepLast or olderThan is required"}, problemStrings(problems))
+}
+
+func Test_ParseDocument_ReportsSyntaxErrorsWithLineNumbers(t *testing.T) {
+	_, err := ParseDocument("perfiz.yml", []byte("karateFeaturesDir: \"karate-features\"\nfeatures:\n  - karateFile: a\n karateEnv: b\n"))
+	assert.Equal(t, "perfiz.yml:3: did not find expected key", err.Error())
+}
+
+func problemStrings(problems Problems) []string {
+	var lines []string
+	for _, problem := range problems {
+		lines = append(lines, problem.String())
+	}
+	return lines
+}
@@ -2,21 +2,57 @@ package constants
 
 const (
 	PERFIZ_HOME_ENV_VARIABLE        = "PERFIZ_HOME"
+	COMPOSE_ENV_VARIABLE            = "PERFIZ_COMPOSE"
+	RUNTIME_ENV_VARIABLE            = "PERFIZ_RUNTIME"
+	DOCKER_HOST_ENV_VARIABLE        = "DOCKER_HOST"
+	DOCKER_CONTEXT_ENV_VARIABLE     = "DOCKER_CONTEXT"
+	DOCKER_CONFIG_ENV_VARIABLE      = "DOCKER_CONFIG"
+	DOCKER_TLS_VERIFY_ENV_VARIABLE  = "DOCKER_TLS_VERIFY"
+	DOCKER_CERT_PATH_ENV_VARIABLE   = "DOCKER_CERT_PATH"
 	DEFAULT_CONFIG_FILE             = "perfiz.yml"
 	PERFIZ_FOLDER                   = "./perfiz"
 	GATLING_CONF                    = "gatling.conf"
 	GATLING_CONF_PATH               = PERFIZ_FOLDER + "/gatling/"
 	GATLING_RESULTS_DIR             = "perfiz/gatling_data/results"
+	HISTORY_FILE                    = PERFIZ_FOLDER + "/history.jsonl"
+	PROJECT_LABEL                   = "org.znsio.perfiz.project"
+	CONTAINER_LOG                   = "container.log"
 	GRAFANA_DASHBOARDS_DIRECTORY    = PERFIZ_FOLDER + "/dashboards"
 	PROMETHEUS_CONFIG_DIR           = PERFIZ_FOLDER + "/prometheus"
 	PROMETHEUS_CONFIG               = PROMETHEUS_CONFIG_DIR + "/prometheus.yml"
+	GRAFANA_DATA_DIR                = PERFIZ_FOLDER + "/grafana_data"
+	INFLUXDB_DATA_DIR               = PERFIZ_FOLDER + "/influxdb_data"
+	PROMETHEUS_DATA_DIR             = PERFIZ_FOLDER + "/prometheus_data"
+	GATLING_DATA_DIR                = PERFIZ_FOLDER + "/gatling_data"
+	SNAPSHOTS_DIR                   = PERFIZ_FOLDER + "/snapshots"
+	PERFIZ_NETWORK                  = "perfiz-network"
+	GATLING_CONTAINER               = "perfiz-gatling"
+	GRAFANA_HEALTH_URL              = "http://localhost:3000/api/health"
+	PROMETHEUS_READY_URL            = "http://localhost:9090/-/ready"
+	INFLUXDB_PING_URL               = "http://localhost:8086/ping"
 	DOCKER_COMPOSE_ENV_FILE         = "/.env"
 	DOCKER_MAJOR_VERSION            = 20
 	DOCKER_MINOR_VERSION            = 10
+	PODMAN_MAJOR_VERSION            = 3
+	PODMAN_MINOR_VERSION            = 0
 	DOCKER_COMPOSE_MAJOR_VERSION    = 1
 	DOCKER_COMPOSE_MINOR_VERSION    = 29
 	PERFIZ_CLI_VERSION              = "0.0.25"
+	MAVEN_IMAGE                     = "docker.io/library/maven:3.8-jdk-8"
+	MAVEN_REPO_VOLUME               = "perfiz-maven-repo"
 	PERFIZ_GATLING_SIMULATION_CLASS = "org.znsio.perfiz.PerfizSimulation"
 
 	SKIP_TEMPLATE_MESSAGE = " is already present. Skipping."
+
+	EXIT_CODE_ERROR                = 1
+	EXIT_CODE_CONFIG_ERROR         = 2
+	EXIT_CODE_INFRASTRUCTURE_ERROR = 3
+	EXIT_CODE_TEST_FAILED          = 4
+	EXIT_CODE_CONTAINER_KILLED     = 5
+	EXIT_CODE_ASSERTIONS_FAILED    = 6
+
+	GATLING_ASSERTIONS_FAILED_MESSAGE          = "assertions failed"
+	CONTAINER_COMMAND_NOT_EXECUTABLE_EXIT_CODE = 126
+	CONTAINER_COMMAND_NOT_FOUND_EXIT_CODE      = 127
+	CONTAINER_KILLED_EXIT_CODE                 = 137
 )
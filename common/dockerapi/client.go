@@ -0,0 +1,199 @@
+package dockerapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// API_VERSION is the version of the Docker Engine API of Docker 20.10, which Podman
+	// serves as well.
+	API_VERSION  = "v1.41"
+	DEFAULT_HOST = "unix:///var/run/docker.sock"
+)
+
+// Client talks to the Docker Engine API of a daemon, or of Podman's compatible service.
+type Client struct {
+	host       string
+	hostUrl    *url.URL
+	baseUrl    string
+	httpClient *http.Client
+}
+
+// APIError is an error response of the API, such as a container name that is in use.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (err *APIError) Error() string {
+	return err.Message + " (status " + strconv.Itoa(err.StatusCode) + ")"
+}
+
+// IsNotFound tells whether err is the API answering that what was asked for, such as a
+// network or an image, does not exist.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// NewClient returns a client for a host as in DOCKER_HOST: unix:///path/to/socket,
+// tcp://host:port for a daemon listening on a port, or ssh://user@host for a daemon on
+// a host that ssh can log in to. An empty host is DEFAULT_HOST. A tcp:// host is
+// talked to over TLS when tlsConfig is given, and over plain HTTP otherwise.
+func NewClient(host string, tlsConfig *tls.Config) (*Client, error) {
+	if host == "" {
+		host = DEFAULT_HOST
+	}
+	hostUrl, parseErr := url.Parse(host)
+	if parseErr != nil {
+		return nil, errors.New("invalid docker host " + host + ": " + parseErr.Error())
+	}
+	switch hostUrl.Scheme {
+	case "unix":
+		socket := hostUrl.Path
+		transport := &http.Transport{
+			DialContext: func(ctx context.Context, _ string, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", socket)
+			},
+		}
+		return &Client{host: host, hostUrl: hostUrl, baseUrl: "http://docker", httpClient: &http.Client{Transport: transport}}, nil
+	case "tcp", "http":
+		if hostUrl.Host == "" {
+			return nil, errors.New("invalid docker host " + host + ": no address")
+		}
+		if tlsConfig != nil {
+			transport := &http.Transport{TLSClientConfig: tlsConfig}
+			return &Client{host: host, hostUrl: hostUrl, baseUrl: "https://" + hostUrl.Host, httpClient: &http.Client{Transport: transport}}, nil
+		}
+		return &Client{host: host, hostUrl: hostUrl, baseUrl: "http://" + hostUrl.Host, httpClient: &http.Client{}}, nil
+	case "ssh":
+		if hostUrl.Hostname() == "" {
+			return nil, errors.New("invalid docker host " + host + ": no address")
+		}
+		transport := &http.Transport{DialContext: sshDialer(hostUrl)}
+		return &Client{host: host, hostUrl: hostUrl, baseUrl: "http://docker", httpClient: &http.Client{Transport: transport}}, nil
+	}
+	return nil, errors.New("unsupported docker host " + host + ", please use unix://, tcp:// or ssh://")
+}
+
+func (client *Client) Host() string {
+	return client.host
+}
+
+// IsRemote tells whether the daemon may run on another machine, which does not share
+// the filesystem containers would bind mount from. A daemon reached at localhost or a
+// loopback address runs on this machine, whatever the scheme.
+func (client *Client) IsRemote() bool {
+	if client.hostUrl.Scheme == "unix" {
+		return false
+	}
+	hostName := client.hostUrl.Hostname()
+	if hostName == "localhost" {
+		return false
+	}
+	ip := net.ParseIP(hostName)
+	return ip == nil || !ip.IsLoopback()
+}
+
+// HostName is the name of the machine the daemon runs on, where the ports containers
+// publish can be reached.
+func (client *Client) HostName() string {
+	if !client.IsRemote() {
+		return "localhost"
+	}
+	return client.hostUrl.Hostname()
+}
+
+// do sends a request to the API and returns the response when it succeeded, and an
+// APIError when the API answered with an error. A body that is an io.Reader is sent as
+// a tar stream, any other body as JSON.
+func (client *Client) do(method string, path string, query url.Values, body interface{}) (*http.Response, error) {
+	var bodyReader io.Reader
+	contentType := "application/json"
+	switch body := body.(type) {
+	case nil:
+	case io.Reader:
+		bodyReader = body
+		contentType = "application/x-tar"
+	default:
+		bodyJson, marshalErr := json.Marshal(body)
+		if marshalErr != nil {
+			return nil, marshalErr
+		}
+		bodyReader = bytes.NewReader(bodyJson)
+	}
+	requestUrl := client.baseUrl + "/" + API_VERSION + path
+	if len(query) > 0 {
+		requestUrl += "?" + query.Encode()
+	}
+	request, requestErr := http.NewRequest(method, requestUrl, bodyReader)
+	if requestErr != nil {
+		return nil, requestErr
+	}
+	if bodyReader != nil {
+		request.Header.Set("Content-Type", contentType)
+	}
+	response, responseErr := client.httpClient.Do(request)
+	if responseErr != nil {
+		return nil, errors.New("unable to reach " + client.host + ": " + responseErr.Error())
+	}
+	if response.StatusCode >= 400 {
+		defer response.Body.Close()
+		return nil, readAPIError(response)
+	}
+	return response, nil
+}
+
+// call is do for requests whose response, if any, is a JSON object to decode into result.
+func (client *Client) call(method string, path string, query url.Values, body interface{}, result interface{}) error {
+	response, err := client.do(method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if result == nil {
+		io.Copy(ioutil.Discard, response.Body)
+		return nil
+	}
+	if decodeErr := json.NewDecoder(response.Body).Decode(result); decodeErr != nil {
+		return errors.New("unexpected response to " + method + " " + path + ": " + decodeErr.Error())
+	}
+	return nil
+}
+
+func readAPIError(response *http.Response) error {
+	responseBody, _ := ioutil.ReadAll(response.Body)
+	var errorResponse struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(responseBody, &errorResponse) != nil || errorResponse.Message == "" {
+		errorResponse.Message = strings.TrimSpace(string(responseBody))
+	}
+	return &APIError{StatusCode: response.StatusCode, Message: errorResponse.Message}
+}
+
+// Network is a network as inspected through the API.
+type Network struct {
+	Id   string `json:"Id"`
+	Name string `json:"Name"`
+}
+
+func (client *Client) InspectNetwork(name string) (*Network, error) {
+	network := &Network{}
+	if err := client.call(http.MethodGet, "/networks/"+url.PathEscape(name), nil, nil, network); err != nil {
+		return nil, err
+	}
+	return network, nil
+}
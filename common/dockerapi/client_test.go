@@ -0,0 +1,99 @@
+package dockerapi
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAPI serves the API from handlers by method and path, without the API version.
+func fakeAPI(t *testing.T, handlers map[string]http.HandlerFunc) *Client {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		path := strings.TrimPrefix(request.URL.Path, "/"+API_VERSION)
+		handler, found := handlers[request.Method+" "+path]
+		if !found {
+			writer.WriteHeader(http.StatusNotFound)
+			writer.Write([]byte(`{"message":"page not found"}`))
+			return
+		}
+		handler(writer, request)
+	}))
+	t.Cleanup(server.Close)
+	client, err := NewClient("tcp://"+server.Listener.Addr().String(), nil)
+	assert.Nil(t, err)
+	return client
+}
+
+func Test_NewClient_RejectsUnsupportedHosts(t *testing.T) {
+	_, err := NewClient("npipe:////./pipe/docker_engine", nil)
+	assert.Equal(t, "unsupported docker host npipe:////./pipe/docker_engine, please use unix://, tcp:// or ssh://", err.Error())
+	_, err = NewClient("tcp://", nil)
+	assert.Equal(t, "invalid docker host tcp://: no address", err.Error())
+}
+
+func Test_InspectNetwork_TalksToUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	listener, listenErr := net.Listen("unix", socket)
+	assert.Nil(t, listenErr)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "/"+API_VERSION+"/networks/perfiz-network", request.URL.Path)
+		writer.Write([]byte(`{"Id":"8e3c1f","Name":"perfiz-network"}`))
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	client, clientErr := NewClient("unix://"+socket, nil)
+	assert.Nil(t, clientErr)
+	network, err := client.InspectNetwork("perfiz-network")
+	assert.Nil(t, err)
+	assert.Equal(t, "8e3c1f", network.Id)
+}
+
+func Test_InspectNetwork_ReturnsNotFoundForMissingNetwork(t *testing.T) {
+	client := fakeAPI(t, map[string]http.HandlerFunc{
+		"GET /networks/perfiz-network": func(writer http.ResponseWriter, request *http.Request) {
+			writer.WriteHeader(http.StatusNotFound)
+			writer.Write([]byte(`{"message":"network perfiz-network not found"}`))
+		},
+	})
+	_, err := client.InspectNetwork("perfiz-network")
+	assert.True(t, IsNotFound(err))
+	assert.Equal(t, "network perfiz-network not found (status 404)", err.Error())
+}
+
+func Test_InspectNetwork_ReturnsErrorWhenDaemonIsUnreachable(t *testing.T) {
+	client, _ := NewClient("unix://"+filepath.Join(t.TempDir(), "missing.sock"), nil)
+	_, err := client.InspectNetwork("perfiz-network")
+	assert.False(t, IsNotFound(err))
+	assert.Contains(t, err.Error(), "unable to reach unix://")
+}
+
+func Test_HostName_IsMachineOfRemoteDaemon(t *testing.T) {
+	local, _ := NewClient("unix:///var/run/docker.sock", nil)
+	assert.False(t, local.IsRemote())
+	assert.Equal(t, "localhost", local.HostName())
+	remote, _ := NewClient("ssh://perfiz@loadgen.example.com:2222", nil)
+	assert.True(t, remote.IsRemote())
+	assert.Equal(t, "loadgen.example.com", remote.HostName())
+}
+
+func Test_IsRemote_IsFalseForLoopbackHosts(t *testing.T) {
+	for _, host := range []string{"tcp://localhost:2375", "tcp://127.0.0.1:2375", "tcp://[::1]:2375"} {
+		client, _ := NewClient(host, nil)
+		assert.False(t, client.IsRemote(), host)
+	}
+	remote, _ := NewClient("tcp://192.168.1.20:2376", nil)
+	assert.True(t, remote.IsRemote())
+}
+
+func Test_sshArgs_RunDialStdioAsUserOnPort(t *testing.T) {
+	hostUrl, _ := url.Parse("ssh://perfiz@loadgen.example.com:2222")
+	assert.Equal(t, []string{"-p", "2222", "--", "perfiz@loadgen.example.com", "docker", "system", "dial-stdio"}, sshArgs(hostUrl))
+}
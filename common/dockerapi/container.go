@@ -0,0 +1,195 @@
+package dockerapi
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ContainerConfig is what a container is created from, as docker run would create it.
+type ContainerConfig struct {
+	Image      string            `json:"Image"`
+	Cmd        []string          `json:"Cmd"`
+	Env        []string          `json:"Env,omitempty"`
+	WorkingDir string            `json:"WorkingDir,omitempty"`
+	User       string            `json:"User,omitempty"`
+	Labels     map[string]string `json:"Labels,omitempty"`
+	HostConfig HostConfig        `json:"HostConfig"`
+}
+
+type HostConfig struct {
+	Binds       []string `json:"Binds,omitempty"`
+	NetworkMode string   `json:"NetworkMode,omitempty"`
+	// UsernsMode is keep-id for Podman to run the container as the user.
+	UsernsMode string `json:"UsernsMode,omitempty"`
+}
+
+// ContainerState is the state of a container as inspected through the API.
+type ContainerState struct {
+	Status    string `json:"Status"`
+	ExitCode  int    `json:"ExitCode"`
+	OOMKilled bool   `json:"OOMKilled"`
+}
+
+// CreateContainer creates a container and returns its id. The image is pulled when it
+// is not there yet, as docker run does.
+func (client *Client) CreateContainer(name string, config *ContainerConfig) (string, error) {
+	id, createErr := client.createContainer(name, config)
+	if !IsNotFound(createErr) {
+		return id, createErr
+	}
+	if pullErr := client.PullImage(config.Image); pullErr != nil {
+		return "", pullErr
+	}
+	return client.createContainer(name, config)
+}
+
+func (client *Client) createContainer(name string, config *ContainerConfig) (string, error) {
+	var created struct {
+		Id string `json:"Id"`
+	}
+	if err := client.call(http.MethodPost, "/containers/create", url.Values{"name": {name}}, config, &created); err != nil {
+		return "", err
+	}
+	return created.Id, nil
+}
+
+// PullImage pulls an image such as maven:3.8-jdk-8, failing when the pull does, which
+// the API tells in the progress it streams rather than in its status.
+func (client *Client) PullImage(image string) error {
+	repository, tag := splitImage(image)
+	response, err := client.do(http.MethodPost, "/images/create", url.Values{"fromImage": {repository}, "tag": {tag}}, nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	decoder := json.NewDecoder(response.Body)
+	for {
+		var progress struct {
+			Error string `json:"error"`
+		}
+		decodeErr := decoder.Decode(&progress)
+		if decodeErr == io.EOF {
+			return nil
+		}
+		if decodeErr != nil {
+			return errors.New("error pulling " + image + ": " + decodeErr.Error())
+		}
+		if progress.Error != "" {
+			return errors.New("error pulling " + image + ": " + progress.Error)
+		}
+	}
+}
+
+// splitImage splits an image into its repository and tag, which is latest when none is
+// given. A colon before the last slash is the port of a registry, not a tag.
+func splitImage(image string) (string, string) {
+	colon := strings.LastIndex(image, ":")
+	if colon < 0 || colon < strings.LastIndex(image, "/") {
+		return image, "latest"
+	}
+	return image[:colon], image[colon+1:]
+}
+
+func (client *Client) StartContainer(id string) error {
+	return client.call(http.MethodPost, "/containers/"+id+"/start", nil, nil, nil)
+}
+
+// ContainerLogs returns the stdout and stderr of a container. Following the logs, both
+// end once the container has stopped.
+func (client *Client) ContainerLogs(id string, follow bool) (io.ReadCloser, io.ReadCloser, error) {
+	query := url.Values{"stdout": {"1"}, "stderr": {"1"}}
+	if follow {
+		query.Set("follow", "1")
+	}
+	response, err := client.do(http.MethodGet, "/containers/"+id+"/logs", query, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	stdoutReader, stdoutWriter := io.Pipe()
+	stderrReader, stderrWriter := io.Pipe()
+	go func() {
+		demuxErr := demultiplex(response.Body, stdoutWriter, stderrWriter)
+		response.Body.Close()
+		stdoutWriter.CloseWithError(demuxErr)
+		stderrWriter.CloseWithError(demuxErr)
+	}()
+	return stdoutReader, stderrReader, nil
+}
+
+// demultiplex splits the logs of a container without a terminal, which come as frames
+// of an 8 byte header, telling the stream and the length, followed by the output.
+func demultiplex(multiplexed io.Reader, stdout io.Writer, stderr io.Writer) error {
+	reader := bufio.NewReader(multiplexed)
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		output := stdout
+		if header[0] == 2 {
+			output = stderr
+		}
+		if _, err := io.CopyN(output, reader, int64(binary.BigEndian.Uint32(header[4:]))); err != nil {
+			return err
+		}
+	}
+}
+
+// WaitContainer waits until a container that was started is no longer running and
+// returns its exit code.
+func (client *Client) WaitContainer(id string) (int, error) {
+	var waited struct {
+		StatusCode int `json:"StatusCode"`
+		Error      *struct {
+			Message string `json:"Message"`
+		} `json:"Error"`
+	}
+	if err := client.call(http.MethodPost, "/containers/"+id+"/wait", nil, nil, &waited); err != nil {
+		return -1, err
+	}
+	if waited.Error != nil && waited.Error.Message != "" {
+		return -1, errors.New("error waiting for container: " + waited.Error.Message)
+	}
+	return waited.StatusCode, nil
+}
+
+func (client *Client) InspectContainer(id string) (*ContainerState, error) {
+	var inspected struct {
+		State ContainerState `json:"State"`
+	}
+	if err := client.call(http.MethodGet, "/containers/"+id+"/json", nil, nil, &inspected); err != nil {
+		return nil, err
+	}
+	return &inspected.State, nil
+}
+
+// RemoveContainer removes a container, killing it first when it is still running.
+func (client *Client) RemoveContainer(id string) error {
+	return client.call(http.MethodDelete, "/containers/"+id, url.Values{"force": {"1"}}, nil, nil)
+}
+
+// CopyToContainer extracts a tar stream, which may be compressed, into a directory of a
+// container. Copying into a container that was created but not started yet ships files
+// to a daemon that does not share the filesystem.
+func (client *Client) CopyToContainer(id string, dir string, content io.Reader) error {
+	return client.call(http.MethodPut, "/containers/"+id+"/archive", url.Values{"path": {dir}}, content, nil)
+}
+
+// CopyFromContainer returns a tar stream of a file or directory of a container, which
+// may have stopped. The stream holds the directory itself, not just what is in it.
+func (client *Client) CopyFromContainer(id string, path string) (io.ReadCloser, error) {
+	response, err := client.do(http.MethodGet, "/containers/"+id+"/archive", url.Values{"path": {path}}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return response.Body, nil
+}
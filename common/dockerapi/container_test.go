@@ -0,0 +1,151 @@
+package dockerapi
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func frame(stream byte, output string) []byte {
+	header := make([]byte, 8)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(output)))
+	return append(header, output...)
+}
+
+func Test_CreateContainer_PullsMissingImage(t *testing.T) {
+	pulled := false
+	var created ContainerConfig
+	client := fakeAPI(t, map[string]http.HandlerFunc{
+		"POST /containers/create": func(writer http.ResponseWriter, request *http.Request) {
+			assert.Equal(t, "perfiz-gatling", request.URL.Query().Get("name"))
+			if !pulled {
+				writer.WriteHeader(http.StatusNotFound)
+				writer.Write([]byte(`{"message":"No such image: maven:3.8-jdk-8"}`))
+				return
+			}
+			json.NewDecoder(request.Body).Decode(&created)
+			writer.WriteHeader(http.StatusCreated)
+			writer.Write([]byte(`{"Id":"4f2a9c","Warnings":[]}`))
+		},
+		"POST /images/create": func(writer http.ResponseWriter, request *http.Request) {
+			assert.Equal(t, "maven", request.URL.Query().Get("fromImage"))
+			assert.Equal(t, "3.8-jdk-8", request.URL.Query().Get("tag"))
+			pulled = true
+			writer.Write([]byte(`{"status":"Pulling from library/maven"}` + "\n" + `{"status":"Download complete"}` + "\n"))
+		},
+	})
+	config := &ContainerConfig{
+		Image:      "maven:3.8-jdk-8",
+		Cmd:        []string{"mvn", "gatling:test"},
+		User:       "1000:1000",
+		HostConfig: HostConfig{Binds: []string{"/home/user/project:/usr/src/project"}, NetworkMode: "perfiz-network"},
+	}
+	id, err := client.CreateContainer("perfiz-gatling", config)
+	assert.Nil(t, err)
+	assert.Equal(t, "4f2a9c", id)
+	assert.True(t, pulled)
+	assert.Equal(t, *config, created)
+}
+
+func Test_PullImage_ReturnsErrorStreamedByAPI(t *testing.T) {
+	client := fakeAPI(t, map[string]http.HandlerFunc{
+		"POST /images/create": func(writer http.ResponseWriter, request *http.Request) {
+			writer.Write([]byte(`{"status":"Pulling from library/maven"}` + "\n" + `{"error":"manifest unknown"}` + "\n"))
+		},
+	})
+	err := client.PullImage("maven:3.8-jdk-99")
+	assert.Equal(t, "error pulling maven:3.8-jdk-99: manifest unknown", err.Error())
+}
+
+func Test_CreateContainer_ReturnsConflictForNameInUse(t *testing.T) {
+	client := fakeAPI(t, map[string]http.HandlerFunc{
+		"POST /containers/create": func(writer http.ResponseWriter, request *http.Request) {
+			writer.WriteHeader(http.StatusConflict)
+			writer.Write([]byte(`{"message":"Conflict. The container name \"/perfiz-gatling\" is already in use"}`))
+		},
+	})
+	_, err := client.CreateContainer("perfiz-gatling", &ContainerConfig{Image: "maven:3.8-jdk-8"})
+	assert.Equal(t, `Conflict. The container name "/perfiz-gatling" is already in use (status 409)`, err.Error())
+}
+
+func Test_ContainerLogs_SplitsStdoutAndStderr(t *testing.T) {
+	client := fakeAPI(t, map[string]http.HandlerFunc{
+		"GET /containers/4f2a9c/logs": func(writer http.ResponseWriter, request *http.Request) {
+			assert.Equal(t, "1", request.URL.Query().Get("follow"))
+			writer.Write(frame(1, "Simulation started\n"))
+			writer.Write(frame(2, "[WARNING] deprecated\n"))
+			writer.Write(frame(1, "Simulation finished\n"))
+		},
+	})
+	stdout, stderr, err := client.ContainerLogs("4f2a9c", true)
+	assert.Nil(t, err)
+	stderrOutput := make(chan []byte)
+	go func() {
+		output, _ := ioutil.ReadAll(stderr)
+		stderrOutput <- output
+	}()
+	stdoutOutput, stdoutErr := ioutil.ReadAll(stdout)
+	assert.Nil(t, stdoutErr)
+	assert.Equal(t, "Simulation started\nSimulation finished\n", string(stdoutOutput))
+	assert.Equal(t, "[WARNING] deprecated\n", string(<-stderrOutput))
+}
+
+func Test_WaitContainer_ReturnsExitCode(t *testing.T) {
+	client := fakeAPI(t, map[string]http.HandlerFunc{
+		"POST /containers/4f2a9c/wait": func(writer http.ResponseWriter, request *http.Request) {
+			writer.Write([]byte(`{"StatusCode":137,"Error":null}`))
+		},
+	})
+	exitCode, err := client.WaitContainer("4f2a9c")
+	assert.Nil(t, err)
+	assert.Equal(t, 137, exitCode)
+}
+
+func Test_InspectContainer_ReturnsState(t *testing.T) {
+	client := fakeAPI(t, map[string]http.HandlerFunc{
+		"GET /containers/4f2a9c/json": func(writer http.ResponseWriter, request *http.Request) {
+			writer.Write([]byte(`{"Id":"4f2a9c","State":{"Status":"exited","ExitCode":137,"OOMKilled":true}}`))
+		},
+	})
+	state, err := client.InspectContainer("4f2a9c")
+	assert.Nil(t, err)
+	assert.Equal(t, &ContainerState{Status: "exited", ExitCode: 137, OOMKilled: true}, state)
+}
+
+func Test_splitImage_TellsRegistryPortFromTag(t *testing.T) {
+	repository, tag := splitImage("localhost:5000/maven")
+	assert.Equal(t, "localhost:5000/maven", repository)
+	assert.Equal(t, "latest", tag)
+	repository, tag = splitImage("docker.io/library/maven:3.8-jdk-8")
+	assert.Equal(t, "docker.io/library/maven", repository)
+	assert.Equal(t, "3.8-jdk-8", tag)
+}
+
+func Test_CopyToContainer_SendsTarStream(t *testing.T) {
+	client := fakeAPI(t, map[string]http.HandlerFunc{
+		"PUT /containers/4f2a9c/archive": func(writer http.ResponseWriter, request *http.Request) {
+			assert.Equal(t, "/", request.URL.Query().Get("path"))
+			assert.Equal(t, "application/x-tar", request.Header.Get("Content-Type"))
+			content, _ := ioutil.ReadAll(request.Body)
+			assert.Equal(t, "tar stream", string(content))
+		},
+	})
+	assert.Nil(t, client.CopyToContainer("4f2a9c", "/", strings.NewReader("tar stream")))
+}
+
+func Test_CopyFromContainer_ReturnsNotFoundForMissingPath(t *testing.T) {
+	client := fakeAPI(t, map[string]http.HandlerFunc{
+		"GET /containers/4f2a9c/archive": func(writer http.ResponseWriter, request *http.Request) {
+			writer.WriteHeader(http.StatusNotFound)
+			writer.Write([]byte(`{"message":"Could not find the file /usr/src/performance-testing/results in container 4f2a9c"}`))
+		},
+	})
+	_, err := client.CopyFromContainer("4f2a9c", "/usr/src/performance-testing/results")
+	assert.True(t, IsNotFound(err))
+}
@@ -0,0 +1,77 @@
+package dockerapi
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Endpoint is where the daemon of a docker context is reached.
+type Endpoint struct {
+	Host string
+	// TLSConfig is nil when the context has no TLS material and does not skip TLS verification.
+	TLSConfig *tls.Config
+}
+
+// ContextEndpoint is the endpoint of a docker context, as docker context use selects
+// it, in the docker config directory such as ~/.docker. Without a name it is the
+// context in use. The default context has no host of its own, so an empty Endpoint is
+// returned for it. The TLS material of a context is stored by docker context create
+// under contexts/tls.
+func ContextEndpoint(dockerConfigDir string, name string) (Endpoint, error) {
+	if name == "" {
+		configJson, readErr := ioutil.ReadFile(filepath.Join(dockerConfigDir, "config.json"))
+		if os.IsNotExist(readErr) {
+			return Endpoint{}, nil
+		}
+		if readErr != nil {
+			return Endpoint{}, readErr
+		}
+		var config struct {
+			CurrentContext string `json:"currentContext"`
+		}
+		if err := json.Unmarshal(configJson, &config); err != nil {
+			return Endpoint{}, errors.New(filepath.Join(dockerConfigDir, "config.json") + ": " + err.Error())
+		}
+		name = config.CurrentContext
+	}
+	if name == "" || name == "default" {
+		return Endpoint{}, nil
+	}
+	hash := sha256.Sum256([]byte(name))
+	contextId := hex.EncodeToString(hash[:])
+	metaFile := filepath.Join(dockerConfigDir, "contexts", "meta", contextId, "meta.json")
+	metaJson, readErr := ioutil.ReadFile(metaFile)
+	if os.IsNotExist(readErr) {
+		return Endpoint{}, errors.New("docker context " + name + " not found")
+	}
+	if readErr != nil {
+		return Endpoint{}, readErr
+	}
+	var meta struct {
+		Endpoints struct {
+			Docker struct {
+				Host          string `json:"Host"`
+				SkipTLSVerify bool   `json:"SkipTLSVerify"`
+			} `json:"docker"`
+		} `json:"Endpoints"`
+	}
+	if err := json.Unmarshal(metaJson, &meta); err != nil {
+		return Endpoint{}, errors.New(metaFile + ": " + err.Error())
+	}
+	endpoint := Endpoint{Host: meta.Endpoints.Docker.Host}
+	tlsDir := filepath.Join(dockerConfigDir, "contexts", "tls", contextId, "docker")
+	if _, statErr := os.Stat(tlsDir); statErr == nil || meta.Endpoints.Docker.SkipTLSVerify {
+		tlsConfig, tlsErr := LoadTLSConfig(tlsDir, !meta.Endpoints.Docker.SkipTLSVerify)
+		if tlsErr != nil {
+			return Endpoint{}, errors.New("docker context " + name + ": " + tlsErr.Error())
+		}
+		endpoint.TLSConfig = tlsConfig
+	}
+	return endpoint, nil
+}
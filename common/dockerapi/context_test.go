@@ -0,0 +1,63 @@
+package dockerapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeContext(t *testing.T, dockerConfigDir string, name string, host string) {
+	hash := sha256.Sum256([]byte(name))
+	metaDir := filepath.Join(dockerConfigDir, "contexts", "meta", hex.EncodeToString(hash[:]))
+	assert.Nil(t, os.MkdirAll(metaDir, 0755))
+	meta := `{"Name":"` + name + `","Metadata":{},"Endpoints":{"docker":{"Host":"` + host + `","SkipTLSVerify":false}}}`
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(metaDir, "meta.json"), []byte(meta), 0644))
+}
+
+func Test_ContextEndpoint_ReturnsHostOfContextInUse(t *testing.T) {
+	dockerConfigDir := t.TempDir()
+	writeContext(t, dockerConfigDir, "loadgen", "ssh://perfiz@loadgen.example.com")
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dockerConfigDir, "config.json"), []byte(`{"auths":{},"currentContext":"loadgen"}`), 0644))
+
+	endpoint, err := ContextEndpoint(dockerConfigDir, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "ssh://perfiz@loadgen.example.com", endpoint.Host)
+	assert.Nil(t, endpoint.TLSConfig)
+}
+
+func Test_ContextEndpoint_LoadsTLSMaterialOfContext(t *testing.T) {
+	dockerConfigDir := t.TempDir()
+	writeContext(t, dockerConfigDir, "loadgen", "tcp://loadgen.example.com:2376")
+	hash := sha256.Sum256([]byte("loadgen"))
+	tlsDir := filepath.Join(dockerConfigDir, "contexts", "tls", hex.EncodeToString(hash[:]), "docker")
+	assert.Nil(t, os.MkdirAll(tlsDir, 0755))
+	writeCA(t, tlsDir, httptest.NewTLSServer(http.NotFoundHandler()))
+
+	endpoint, err := ContextEndpoint(dockerConfigDir, "loadgen")
+	assert.Nil(t, err)
+	assert.Equal(t, "tcp://loadgen.example.com:2376", endpoint.Host)
+	assert.NotNil(t, endpoint.TLSConfig.RootCAs)
+	assert.False(t, endpoint.TLSConfig.InsecureSkipVerify)
+}
+
+func Test_ContextEndpoint_ReturnsNoHostForDefaultContext(t *testing.T) {
+	dockerConfigDir := t.TempDir()
+	endpoint, err := ContextEndpoint(dockerConfigDir, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "", endpoint.Host)
+	endpoint, err = ContextEndpoint(dockerConfigDir, "default")
+	assert.Nil(t, err)
+	assert.Equal(t, "", endpoint.Host)
+}
+
+func Test_ContextEndpoint_ReturnsErrorForUnknownContext(t *testing.T) {
+	_, err := ContextEndpoint(t.TempDir(), "loadgen")
+	assert.Equal(t, "docker context loadgen not found", err.Error())
+}
@@ -0,0 +1,97 @@
+package dockerapi
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// sshDialer connects to the daemon on a host reached with ssh the way the docker CLI
+// does, through docker system dial-stdio run on that host.
+func sshDialer(hostUrl *url.URL) func(ctx context.Context, network string, address string) (net.Conn, error) {
+	args := sshArgs(hostUrl)
+	return func(context.Context, string, string) (net.Conn, error) {
+		command := exec.Command("ssh", args...)
+		command.Stderr = os.Stderr
+		stdin, stdinErr := command.StdinPipe()
+		if stdinErr != nil {
+			return nil, stdinErr
+		}
+		stdout, stdoutErr := command.StdoutPipe()
+		if stdoutErr != nil {
+			return nil, stdoutErr
+		}
+		if err := command.Start(); err != nil {
+			return nil, err
+		}
+		return &commandConn{command: command, stdin: stdin, stdout: stdout}, nil
+	}
+}
+
+func sshArgs(hostUrl *url.URL) []string {
+	var args []string
+	if hostUrl.Port() != "" {
+		args = append(args, "-p", hostUrl.Port())
+	}
+	destination := hostUrl.Hostname()
+	if hostUrl.User != nil {
+		destination = hostUrl.User.Username() + "@" + destination
+	}
+	return append(args, "--", destination, "docker", "system", "dial-stdio")
+}
+
+// commandConn is a connection over the stdin and stdout of a command.
+type commandConn struct {
+	command *exec.Cmd
+	stdin   io.WriteCloser
+	stdout  io.ReadCloser
+}
+
+func (conn *commandConn) Read(b []byte) (int, error) {
+	return conn.stdout.Read(b)
+}
+
+func (conn *commandConn) Write(b []byte) (int, error) {
+	return conn.stdin.Write(b)
+}
+
+func (conn *commandConn) Close() error {
+	conn.stdin.Close()
+	conn.command.Process.Kill()
+	conn.command.Wait()
+	return nil
+}
+
+func (conn *commandConn) LocalAddr() net.Addr {
+	return commandAddr{}
+}
+
+func (conn *commandConn) RemoteAddr() net.Addr {
+	return commandAddr{}
+}
+
+func (conn *commandConn) SetDeadline(time.Time) error {
+	return nil
+}
+
+func (conn *commandConn) SetReadDeadline(time.Time) error {
+	return nil
+}
+
+func (conn *commandConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
+
+type commandAddr struct{}
+
+func (commandAddr) Network() string {
+	return "command"
+}
+
+func (commandAddr) String() string {
+	return "ssh"
+}
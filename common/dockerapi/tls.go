@@ -0,0 +1,53 @@
+package dockerapi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const (
+	CA_FILE   = "ca.pem"
+	CERT_FILE = "cert.pem"
+	KEY_FILE  = "key.pem"
+)
+
+// LoadTLSConfig is the TLS configuration for a daemon from the files in a directory,
+// named as in DOCKER_CERT_PATH: the CA that signed the daemon's certificate, and the
+// certificate and key of the client. Files that are missing are left out, so that a
+// daemon whose certificate is trusted by the system needs no CA. verify is false to
+// accept any certificate of the daemon, as DOCKER_TLS_VERIFY unset does.
+func LoadTLSConfig(certDir string, verify bool) (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: !verify}
+	caFile := filepath.Join(certDir, CA_FILE)
+	caPem, readErr := ioutil.ReadFile(caFile)
+	if readErr != nil && !os.IsNotExist(readErr) {
+		return nil, readErr
+	}
+	if readErr == nil {
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(caPem) {
+			return nil, errors.New("no certificate found in " + caFile)
+		}
+		config.RootCAs = certPool
+	}
+	certFile := filepath.Join(certDir, CERT_FILE)
+	keyFile := filepath.Join(certDir, KEY_FILE)
+	if !fileExists(certFile) && !fileExists(keyFile) {
+		return config, nil
+	}
+	certificate, loadErr := tls.LoadX509KeyPair(certFile, keyFile)
+	if loadErr != nil {
+		return nil, errors.New("unable to load client certificate from " + certDir + ": " + loadErr.Error())
+	}
+	config.Certificates = []tls.Certificate{certificate}
+	return config, nil
+}
+
+func fileExists(file string) bool {
+	_, err := os.Stat(file)
+	return err == nil
+}
@@ -0,0 +1,53 @@
+package dockerapi
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeCA writes the certificate of a test server as the CA a client is to trust.
+func writeCA(t *testing.T, certDir string, server *httptest.Server) {
+	t.Cleanup(server.Close)
+	caPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(certDir, CA_FILE), caPem, 0644))
+}
+
+func Test_LoadTLSConfig_TalksToDaemonSignedByCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Write([]byte(`{"Id":"8e3c1f","Name":"perfiz-network"}`))
+	}))
+	certDir := t.TempDir()
+	writeCA(t, certDir, server)
+
+	tlsConfig, tlsErr := LoadTLSConfig(certDir, true)
+	assert.Nil(t, tlsErr)
+	client, clientErr := NewClient("tcp://"+server.Listener.Addr().String(), tlsConfig)
+	assert.Nil(t, clientErr)
+	network, err := client.InspectNetwork("perfiz-network")
+	assert.Nil(t, err)
+	assert.Equal(t, "8e3c1f", network.Id)
+}
+
+func Test_LoadTLSConfig_RejectsDaemonNotSignedByCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	tlsConfig, tlsErr := LoadTLSConfig(t.TempDir(), true)
+	assert.Nil(t, tlsErr)
+	client, _ := NewClient("tcp://"+server.Listener.Addr().String(), tlsConfig)
+	_, err := client.InspectNetwork("perfiz-network")
+	assert.Contains(t, err.Error(), "certificate")
+}
+
+func Test_LoadTLSConfig_ReturnsErrorForIncompleteClientCertificate(t *testing.T) {
+	certDir := t.TempDir()
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(certDir, CERT_FILE), []byte("not a certificate"), 0644))
+	_, err := LoadTLSConfig(certDir, true)
+	assert.Contains(t, err.Error(), "unable to load client certificate from "+certDir)
+}
@@ -0,0 +1,80 @@
+package environment
+
+import (
+	cmd "github.com/znsio/perfiz-cli/common/command"
+	"github.com/znsio/perfiz-cli/common/constants"
+	"os/exec"
+)
+
+// Compose is an implementation of Docker Compose: a standalone binary such as the
+// docker-compose of Compose v1, or a plugin of the runtime's CLI such as the docker
+// compose of Compose v2.
+type Compose interface {
+	// Name is v1 for a standalone binary or v2 for a plugin, as chosen with --compose.
+	Name() string
+	Command(args ...string) *exec.Cmd
+	Version() cmd.Command
+	// RequiredVersion is the oldest major and minor version that runs Perfiz, 0 and 0
+	// when any version does.
+	RequiredVersion() (int, int)
+	String() string
+}
+
+// composeCommand is a compose run as program, with args ahead of those of the compose
+// command.
+type composeCommand struct {
+	name                 string
+	program              string
+	args                 []string
+	versionArgs          []string
+	requiredMajorVersion int
+	requiredMinorVersion int
+}
+
+func (compose composeCommand) Name() string {
+	return compose.name
+}
+
+func (compose composeCommand) Command(args ...string) *exec.Cmd {
+	return exec.Command(compose.program, append(append([]string{}, compose.args...), args...)...)
+}
+
+func (compose composeCommand) Version() cmd.Command {
+	return cmd.Create(compose.program, compose.versionArgs...)
+}
+
+func (compose composeCommand) RequiredVersion() (int, int) {
+	return compose.requiredMajorVersion, compose.requiredMinorVersion
+}
+
+func (compose composeCommand) String() string {
+	if len(compose.args) == 0 {
+		return compose.program
+	}
+	return compose.program + " " + compose.args[0]
+}
+
+var (
+	DOCKER_COMPOSE_V1 Compose = composeCommand{"v1", "docker-compose", nil, []string{"--version"},
+		constants.DOCKER_COMPOSE_MAJOR_VERSION, constants.DOCKER_COMPOSE_MINOR_VERSION}
+	DOCKER_COMPOSE_V2 Compose = composeCommand{"v2", "docker", []string{"compose"}, []string{"compose", "version"},
+		constants.DOCKER_COMPOSE_MAJOR_VERSION, constants.DOCKER_COMPOSE_MINOR_VERSION}
+	// podman-compose and the compose plugin of podman print versions in no common
+	// format, so any version that runs will do.
+	PODMAN_COMPOSE_V1 Compose = composeCommand{"v1", "podman-compose", nil, []string{"--version"}, 0, 0}
+	PODMAN_COMPOSE_V2 Compose = composeCommand{"v2", "podman", []string{"compose"}, []string{"compose", "version"}, 0, 0}
+)
+
+// DetectCompose returns the candidate named by choice, or without a choice the first
+// candidate that is installed.
+func DetectCompose(choice string, candidates []Compose) (Compose, error) {
+	var choices []candidate
+	for _, compose := range candidates {
+		choices = append(choices, compose)
+	}
+	index, err := detect("compose", choice, choices)
+	if err != nil {
+		return nil, err
+	}
+	return candidates[index], nil
+}
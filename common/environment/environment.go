@@ -1,11 +1,11 @@
 package environment
 
 import (
+	"encoding/json"
 	"errors"
 	cmd "github.com/znsio/perfiz-cli/common/command"
 	"log"
 	"os"
-	"os/exec"
 	"os/user"
 	"regexp"
 	"strconv"
@@ -21,21 +21,6 @@ func GetEnvVariable(envVariableName string) string {
 	return envVariable
 }
 
-func CheckIfCommandExists(command string, requiredMajorVersion int, requiredMinorVersion int) {
-	path, err := exec.LookPath(command)
-	if err != nil {
-		log.Fatalln(command+" not found, please install. Error: ", err)
-	}
-
-	log.Println(command + " command located: " + path)
-
-	versionCommand := cmd.Create(command, "--version")
-	versionCheckOkay, err := CheckCommandVersion(versionCommand, requiredMajorVersion, requiredMinorVersion)
-	if !versionCheckOkay {
-		log.Fatalln("Error locating " + command + ":" + err.Error())
-	}
-}
-
 func CheckCommandVersion(version cmd.Command, requiredMajorVersion int, requiredMinorVersion int) (bool, error) {
 	versionOutput, versionError := version.Execute()
 	if versionError != nil {
@@ -65,30 +50,131 @@ func CheckCommandVersion(version cmd.Command, requiredMajorVersion int, required
 		" Min version required: " + strconv.Itoa(requiredMajorVersion) + "." + strconv.Itoa(requiredMinorVersion) + ".0")
 }
 
-func GetCommandVersion(command string) string {
-	path, err := exec.LookPath(command)
+func GetUserIdAndGroupId() (string, string) {
+	current, err := user.Current()
+	if err != nil {
+		log.Println("Error getting current user: " + err.Error())
+		log.Fatalln(err)
+	}
+	return current.Uid, current.Gid
+}
+
+// GetGitCommit is the commit checked out in the working directory, or "" outside of a
+// git repository or without git installed.
+func GetGitCommit(revParse cmd.Command) string {
+	commit, err := revParse.Execute()
 	if err != nil {
-		log.Fatalln(command+" not found, please install. Error: ", err)
+		return ""
 	}
+	return strings.TrimSpace(string(commit))
+}
 
-	log.Println(command + " command located: " + path)
+// Container is a container as listed by docker ps.
+type Container struct {
+	Name       string `json:"Names"`
+	Image      string `json:"Image"`
+	State      string `json:"State"`
+	Status     string `json:"Status"`
+	Ports      string `json:"Ports"`
+	RunningFor string `json:"RunningFor"`
+}
 
-	version := exec.Command(command, "--version")
+// portMapping is a published port as podman ps prints it, with the keys of Podman 3
+// or of Podman 4.
+type portMapping struct {
+	HostIP          string `json:"hostIP"`
+	HostIPV4        string `json:"host_ip"`
+	HostPort        int    `json:"hostPort"`
+	HostPortV4      int    `json:"host_port"`
+	ContainerPort   int    `json:"containerPort"`
+	ContainerPortV4 int    `json:"container_port"`
+	Protocol        string `json:"protocol"`
+}
 
-	versionOutput, versionError := version.Output()
-	if versionError != nil {
-		log.Fatalln("Unable to run " + command + " --version, please check your installation.")
+func (port portMapping) String() string {
+	hostIP := port.HostIP + port.HostIPV4
+	if hostIP == "" {
+		hostIP = "0.0.0.0"
 	}
+	return hostIP + ":" + strconv.Itoa(port.HostPort+port.HostPortV4) + "->" + strconv.Itoa(port.ContainerPort+port.ContainerPortV4) + "/" + port.Protocol
+}
 
-	versionString := string(versionOutput)
-	return versionString
+// UnmarshalJSON reads a container as docker ps prints it, where Names and Ports are
+// strings, and as podman ps may print it, where Names is a list of names and Ports a
+// list of port mappings.
+func (container *Container) UnmarshalJSON(data []byte) error {
+	var fields struct {
+		Names      json.RawMessage `json:"Names"`
+		Image      string          `json:"Image"`
+		State      string          `json:"State"`
+		Status     string          `json:"Status"`
+		Ports      json.RawMessage `json:"Ports"`
+		RunningFor string          `json:"RunningFor"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*container = Container{Image: fields.Image, State: fields.State, Status: fields.Status, RunningFor: fields.RunningFor}
+	if len(fields.Names) > 0 && json.Unmarshal(fields.Names, &container.Name) != nil {
+		var names []string
+		if err := json.Unmarshal(fields.Names, &names); err != nil {
+			return err
+		}
+		container.Name = strings.Join(names, ",")
+	}
+	if len(fields.Ports) > 0 && json.Unmarshal(fields.Ports, &container.Ports) != nil {
+		var ports []portMapping
+		if err := json.Unmarshal(fields.Ports, &ports); err != nil {
+			return err
+		}
+		var publishedPorts []string
+		for _, port := range ports {
+			publishedPorts = append(publishedPorts, port.String())
+		}
+		container.Ports = strings.Join(publishedPorts, ", ")
+	}
+	return nil
 }
 
-func GetUserIdAndGroupId() (string, string) {
-	current, err := user.Current()
-	if err != nil {
-		log.Println("Error getting current user: " + err.Error())
-		log.Fatalln(err)
+// Uptime is how long a running container has been up, and "" for one that is not running.
+func (container Container) Uptime() string {
+	if container.State != "running" {
+		return ""
 	}
-	return current.Uid, current.Gid
+	return strings.TrimSuffix(container.RunningFor, " ago")
+}
+
+// Health is healthy, unhealthy or starting for containers with a health check, and ""
+// for those without.
+func (container Container) Health() string {
+	switch {
+	case strings.Contains(container.Status, "(healthy)"):
+		return "healthy"
+	case strings.Contains(container.Status, "(unhealthy)"):
+		return "unhealthy"
+	case strings.Contains(container.Status, "(health: starting)"):
+		return "starting"
+	}
+	return ""
+}
+
+// ListContainers runs a docker ps that prints a JSON object per container, as with
+// --format '{{json .}}'.
+func ListContainers(ps cmd.Command) ([]Container, error) {
+	psOutput, psErr := ps.Execute()
+	if psErr != nil {
+		return nil, psErr
+	}
+	var containers []Container
+	for _, line := range strings.Split(string(psOutput), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var container Container
+		if err := json.Unmarshal([]byte(line), &container); err != nil {
+			return nil, errors.New("unexpected docker ps output: " + line)
+		}
+		containers = append(containers, container)
+	}
+	return containers, nil
 }
@@ -4,6 +4,8 @@ import (
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	cmd "github.com/znsio/perfiz-cli/common/command"
+	"os"
 	"testing"
 )
 
@@ -54,3 +56,143 @@ func Test_CheckCommandVersion_ReturnsFalseWhenThereIsAnErrorRunningVersionComman
 	assert.False(t, commandExists)
 	assert.Equal(t, "Error running version command", error.Error())
 }
+
+func Test_GetGitCommit_ReturnsCommitWithoutTrailingNewline(t *testing.T) {
+	cmdMock := new(CommandMock)
+	cmdMock.On("Execute").Return("3967b7d0c5f1a0e2b4d6f8a1c3e5f7a9b1d3f5e7\n", nil)
+	assert.Equal(t, "3967b7d0c5f1a0e2b4d6f8a1c3e5f7a9b1d3f5e7", GetGitCommit(cmdMock))
+}
+
+func Test_GetGitCommit_ReturnsEmptyOutsideOfGitRepository(t *testing.T) {
+	cmdMock := new(CommandMock)
+	cmdMock.On("Execute").Return("", errors.New("fatal: not a git repository"))
+	assert.Equal(t, "", GetGitCommit(cmdMock))
+}
+
+func Test_ListContainers_ParsesDockerPsJsonLines(t *testing.T) {
+	cmdMock := new(CommandMock)
+	cmdMock.On("Execute").Return(`{"Names":"perfiz_grafana_1","Image":"grafana/grafana:8.1.2","State":"running","Status":"Up 2 hours (healthy)","Ports":"0.0.0.0:3000->3000/tcp","RunningFor":"2 hours ago"}
+{"Names":"perfiz_influxdb_1","Image":"influxdb:1.8","State":"exited","Status":"Exited (1) 5 minutes ago","Ports":"","RunningFor":"2 hours ago"}
+`, nil)
+	containers, err := ListContainers(cmdMock)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(containers))
+	assert.Equal(t, "perfiz_grafana_1", containers[0].Name)
+	assert.Equal(t, "healthy", containers[0].Health())
+	assert.Equal(t, "2 hours", containers[0].Uptime())
+	assert.Equal(t, "exited", containers[1].State)
+	assert.Equal(t, "", containers[1].Health())
+}
+
+func Test_ListContainers_ParsesPodmanPsJsonLines(t *testing.T) {
+	cmdMock := new(CommandMock)
+	cmdMock.On("Execute").Return(`{"Names":["perfiz_grafana_1"],"Image":"docker.io/grafana/grafana:8.1.2","State":"running","Status":"Up 2 hours ago (healthy)","Ports":[{"hostIP":"","containerPort":3000,"hostPort":3000,"protocol":"tcp"}],"RunningFor":"2 hours ago"}
+{"Names":["perfiz_prometheus_1"],"Image":"docker.io/prom/prometheus:v2.29.1","State":"running","Status":"Up 5 minutes ago","Ports":[{"host_ip":"127.0.0.1","container_port":9090,"host_port":9090,"range":1,"protocol":"tcp"}],"RunningFor":"5 minutes ago"}
+{"Names":["perfiz_influxdb_1"],"Image":"docker.io/library/influxdb:1.8","State":"exited","Status":"Exited (1) 5 minutes ago","Ports":null,"RunningFor":"2 hours ago"}
+`, nil)
+	containers, err := ListContainers(cmdMock)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(containers))
+	assert.Equal(t, "perfiz_grafana_1", containers[0].Name)
+	assert.Equal(t, "0.0.0.0:3000->3000/tcp", containers[0].Ports)
+	assert.Equal(t, "healthy", containers[0].Health())
+	assert.Equal(t, "2 hours", containers[0].Uptime())
+	assert.Equal(t, "127.0.0.1:9090->9090/tcp", containers[1].Ports)
+	assert.Equal(t, "", containers[2].Ports)
+	assert.Equal(t, "", containers[2].Uptime())
+}
+
+func newCompose(name string, versionOutput string, versionErr error, requiredMajorVersion int, requiredMinorVersion int) composeMock {
+	version := new(CommandMock)
+	version.On("Execute").Return(versionOutput, versionErr)
+	return composeMock{composeCommand{name, "compose-" + name, nil, nil, requiredMajorVersion, requiredMinorVersion}, version}
+}
+
+type composeMock struct {
+	composeCommand
+	version *CommandMock
+}
+
+func (compose composeMock) Version() cmd.Command {
+	return compose.version
+}
+
+func Test_DetectCompose_PrefersFirstInstalledCandidate(t *testing.T) {
+	candidates := []Compose{
+		newCompose("v2", "", errors.New("docker: 'compose' is not a docker command"), 1, 29),
+		newCompose("v1", "docker-compose version 1.29.2, build 5becea4c", nil, 1, 29),
+	}
+	compose, err := DetectCompose("", candidates)
+	assert.Nil(t, err)
+	assert.Equal(t, "v1", compose.Name())
+}
+
+func Test_DetectCompose_ReturnsChosenCandidate(t *testing.T) {
+	candidates := []Compose{
+		newCompose("v2", "Docker Compose version v2.10.2", nil, 1, 29),
+		newCompose("v1", "docker-compose version 1.29.2, build 5becea4c", nil, 1, 29),
+	}
+	compose, err := DetectCompose("v1", candidates)
+	assert.Nil(t, err)
+	assert.Equal(t, "v1", compose.Name())
+}
+
+func Test_DetectCompose_ReturnsErrorWhenChosenCandidateIsNotInstalled(t *testing.T) {
+	candidates := []Compose{
+		newCompose("v2", "", errors.New("docker: 'compose' is not a docker command"), 1, 29),
+		newCompose("v1", "docker-compose version 1.29.2, build 5becea4c", nil, 1, 29),
+	}
+	_, err := DetectCompose("v2", candidates)
+	assert.Equal(t, "no usable compose found, please install. compose-v2: docker: 'compose' is not a docker command", err.Error())
+}
+
+func Test_DetectCompose_ReturnsErrorForUnknownChoice(t *testing.T) {
+	_, err := DetectCompose("v3", []Compose{newCompose("v2", "", nil, 0, 0), newCompose("v1", "", nil, 0, 0)})
+	assert.Equal(t, "unknown compose v3, please choose one of v2, v1", err.Error())
+}
+
+func Test_DetectCompose_AcceptsAnyVersionWithoutRequiredVersion(t *testing.T) {
+	compose, err := DetectCompose("", []Compose{newCompose("v1", "podman-compose version: 1.0.6\nusing podman version: 4.3.1", nil, 0, 0)})
+	assert.Nil(t, err)
+	assert.Equal(t, "v1", compose.Name())
+}
+
+func Test_ComposeCommand_RunsPluginOfRuntimeForV2(t *testing.T) {
+	assert.Equal(t, []string{"docker", "compose", "up", "-d"}, DOCKER_COMPOSE_V2.Command("up", "-d").Args)
+	assert.Equal(t, []string{"docker-compose", "up", "-d"}, DOCKER_COMPOSE_V1.Command("up", "-d").Args)
+	assert.Equal(t, "podman compose", PODMAN_COMPOSE_V2.String())
+}
+
+func Test_DetectRuntime_PassesOverDockerThatIsPodman(t *testing.T) {
+	docker := new(CommandMock)
+	docker.On("Execute").Return("podman version 4.3.1", nil)
+	podman := new(CommandMock)
+	podman.On("Execute").Return("podman version 4.3.1", nil)
+	candidates := []Runtime{runtimeMock{DOCKER.(containerRuntime), docker}, runtimeMock{PODMAN.(containerRuntime), podman}}
+	runtime, err := DetectRuntime("", candidates)
+	assert.Nil(t, err)
+	assert.Equal(t, "podman", runtime.Name())
+}
+
+func Test_UsernsMode_KeepsUserIdForPodman(t *testing.T) {
+	assert.Equal(t, "", DOCKER.UsernsMode())
+	assert.Equal(t, "keep-id", PODMAN.UsernsMode())
+}
+
+func Test_DefaultHost_IsSocketOfUserForRootlessPodman(t *testing.T) {
+	if os.Getuid() == 0 {
+		t.Skip("rootless only")
+	}
+	os.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	defer os.Unsetenv("XDG_RUNTIME_DIR")
+	assert.Equal(t, "unix:///run/user/1000/podman/podman.sock", PODMAN.DefaultHost())
+}
+
+type runtimeMock struct {
+	containerRuntime
+	version *CommandMock
+}
+
+func (runtime runtimeMock) Version() cmd.Command {
+	return runtime.version
+}
@@ -0,0 +1,162 @@
+package environment
+
+import (
+	"errors"
+	cmd "github.com/znsio/perfiz-cli/common/command"
+	"github.com/znsio/perfiz-cli/common/constants"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// PS_FORMAT makes docker ps and podman ps print a JSON object per container that
+// ListContainers can read, whichever of them is the runtime.
+const PS_FORMAT = `{"Names":{{json .Names}},"Image":{{json .Image}},"State":{{json .State}},"Status":{{json .Status}},"Ports":{{json .Ports}},"RunningFor":{{json .RunningFor}}}`
+
+// Runtime is a container runtime with a docker compatible CLI, Docker or Podman.
+type Runtime interface {
+	// Name is docker or podman, as chosen with --runtime.
+	Name() string
+	Command(args ...string) *exec.Cmd
+	// Create is Command for the functions that take a cmd.Command.
+	Create(args ...string) cmd.Command
+	Version() cmd.Command
+	RequiredVersion() (int, int)
+	// UsernsMode is the user namespace a container runs in so that, running as the user,
+	// it can write to the directories mounted into it. "" is the default namespace.
+	UsernsMode() string
+	// DefaultHost is where the runtime serves the Docker Engine API when DOCKER_HOST is
+	// not set.
+	DefaultHost() string
+	// Composes are the compose implementations that work with the runtime, in the order
+	// they are looked for.
+	Composes() []Compose
+	String() string
+}
+
+type containerRuntime struct {
+	program              string
+	requiredMajorVersion int
+	requiredMinorVersion int
+	usernsMode           string
+	defaultHost          func() string
+	composes             []Compose
+}
+
+func (runtime containerRuntime) Name() string {
+	return runtime.program
+}
+
+func (runtime containerRuntime) Command(args ...string) *exec.Cmd {
+	return exec.Command(runtime.program, args...)
+}
+
+func (runtime containerRuntime) Create(args ...string) cmd.Command {
+	return cmd.Create(runtime.program, args...)
+}
+
+func (runtime containerRuntime) Version() cmd.Command {
+	return cmd.Create(runtime.program, "--version")
+}
+
+func (runtime containerRuntime) RequiredVersion() (int, int) {
+	return runtime.requiredMajorVersion, runtime.requiredMinorVersion
+}
+
+func (runtime containerRuntime) UsernsMode() string {
+	return runtime.usernsMode
+}
+
+func (runtime containerRuntime) DefaultHost() string {
+	return runtime.defaultHost()
+}
+
+func (runtime containerRuntime) Composes() []Compose {
+	return runtime.composes
+}
+
+func (runtime containerRuntime) String() string {
+	return runtime.program
+}
+
+var (
+	DOCKER Runtime = containerRuntime{"docker", constants.DOCKER_MAJOR_VERSION, constants.DOCKER_MINOR_VERSION,
+		"", dockerHost, []Compose{DOCKER_COMPOSE_V2, DOCKER_COMPOSE_V1}}
+	// Rootless Podman maps the user to root in the container unless told to keep its id,
+	// so a container running as the user could not write to the mounted directories.
+	PODMAN Runtime = containerRuntime{"podman", constants.PODMAN_MAJOR_VERSION, constants.PODMAN_MINOR_VERSION,
+		"keep-id", podmanHost, []Compose{PODMAN_COMPOSE_V2, PODMAN_COMPOSE_V1}}
+)
+
+func dockerHost() string {
+	return "unix:///var/run/docker.sock"
+}
+
+// podmanHost is the socket of podman.socket, which is per user for rootless Podman.
+func podmanHost() string {
+	if os.Getuid() == 0 {
+		return "unix:///run/podman/podman.sock"
+	}
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		runtimeDir = "/run/user/" + strconv.Itoa(os.Getuid())
+	}
+	return "unix://" + runtimeDir + "/podman/podman.sock"
+}
+
+// RUNTIMES are the supported runtimes, in the order they are looked for.
+var RUNTIMES = []Runtime{DOCKER, PODMAN}
+
+// DetectRuntime returns the candidate named by choice, or without a choice the first
+// candidate that is installed. A docker that is Podman's docker compatible script is
+// not Docker 20.10 or newer and so is passed over for podman.
+func DetectRuntime(choice string, candidates []Runtime) (Runtime, error) {
+	var choices []candidate
+	for _, runtime := range candidates {
+		choices = append(choices, runtime)
+	}
+	index, err := detect("container runtime", choice, choices)
+	if err != nil {
+		return nil, err
+	}
+	return candidates[index], nil
+}
+
+// candidate is a runtime or compose to detect.
+type candidate interface {
+	Name() string
+	Version() cmd.Command
+	RequiredVersion() (int, int)
+	String() string
+}
+
+func detect(kind string, choice string, candidates []candidate) (int, error) {
+	var names []string
+	var problems []string
+	for index, candidate := range candidates {
+		names = append(names, candidate.Name())
+		if choice != "" && candidate.Name() != choice {
+			continue
+		}
+		err := checkInstalled(candidate)
+		if err == nil {
+			return index, nil
+		}
+		problems = append(problems, candidate.String()+": "+err.Error())
+	}
+	if len(problems) == 0 {
+		return -1, errors.New("unknown " + kind + " " + choice + ", please choose one of " + strings.Join(names, ", "))
+	}
+	return -1, errors.New("no usable " + kind + " found, please install. " + strings.Join(problems, "; "))
+}
+
+func checkInstalled(candidate candidate) error {
+	requiredMajorVersion, requiredMinorVersion := candidate.RequiredVersion()
+	if requiredMajorVersion == 0 && requiredMinorVersion == 0 {
+		_, err := candidate.Version().Execute()
+		return err
+	}
+	_, err := CheckCommandVersion(candidate.Version(), requiredMajorVersion, requiredMinorVersion)
+	return err
+}
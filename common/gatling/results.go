@@ -0,0 +1,104 @@
+package gatling
+
+import (
+	"bufio"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RUN_DIR_TIMESTAMP_FORMAT is how Gatling stamps the name of a run directory,
+// <simulation id>-<timestamp>, with the time the run started in the time zone of the
+// test container, which is UTC.
+const RUN_DIR_TIMESTAMP_FORMAT = "20060102150405.000"
+
+var runDirTimestampRegex = regexp.MustCompile(`-(\d{14})(\d{3})$`)
+
+// RunDirs lists the run directories in a Gatling results folder that contain a
+// simulation.log, oldest first.
+func RunDirs(resultsDir string) ([]string, error) {
+	entries, err := ioutil.ReadDir(resultsDir)
+	if err != nil {
+		return nil, err
+	}
+	var runDirs []string
+	starts := map[string]time.Time{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		runDir := filepath.Join(resultsDir, entry.Name())
+		if _, statErr := os.Stat(filepath.Join(runDir, SIMULATION_LOG)); statErr == nil {
+			runDirs = append(runDirs, runDir)
+			starts[runDir] = RunStart(runDir)
+		}
+	}
+	sort.SliceStable(runDirs, func(i, j int) bool { return starts[runDirs[i]].Before(starts[runDirs[j]]) })
+	return runDirs, nil
+}
+
+// RunStart is when the run in a run directory started, as the RUN record of its
+// simulation.log says. The modification time of a directory changes as it is copied
+// around or written to, so it is only fallen back on when neither the RUN record nor
+// the name of the directory tell.
+func RunStart(runDir string) time.Time {
+	if runRecord, err := readRunRecord(filepath.Join(runDir, SIMULATION_LOG)); err == nil {
+		return runRecord.Start
+	}
+	if match := runDirTimestampRegex.FindStringSubmatch(filepath.Base(runDir)); match != nil {
+		if start, err := time.ParseInLocation(RUN_DIR_TIMESTAMP_FORMAT, match[1]+"."+match[2], time.UTC); err == nil {
+			return start
+		}
+	}
+	if info, err := os.Stat(runDir); err == nil {
+		return info.ModTime()
+	}
+	return time.Time{}
+}
+
+// readRunRecord reads only the RUN record a simulation.log starts with.
+func readRunRecord(simulationLogFile string) (*RunRecord, error) {
+	file, openErr := os.Open(simulationLogFile)
+	if openErr != nil {
+		return nil, openErr
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() {
+		return nil, errors.New(simulationLogFile + ": no RUN record")
+	}
+	fields := strings.Split(strings.TrimRight(scanner.Text(), "\r\n"), "\t")
+	if fields[0] != "RUN" {
+		return nil, errors.New(simulationLogFile + ": no RUN record")
+	}
+	simulationLog := &SimulationLog{}
+	if err := parseRun(fields, simulationLog); err != nil {
+		return nil, err
+	}
+	return &simulationLog.Run, nil
+}
+
+func ParseRunDir(runDir string) (*SimulationLog, error) {
+	return ParseFile(filepath.Join(runDir, SIMULATION_LOG))
+}
+
+// LatestRunDir is the newest run directory in the results folder started since a given
+// time, which is the one a test run started at that time produced.
+func LatestRunDir(resultsDir string, since time.Time) (string, error) {
+	runDirs, err := RunDirs(resultsDir)
+	if err != nil {
+		return "", err
+	}
+	if len(runDirs) > 0 {
+		latest := runDirs[len(runDirs)-1]
+		if !RunStart(latest).Before(since) {
+			return latest, nil
+		}
+	}
+	return "", errors.New("no Gatling results found in " + resultsDir + " since " + since.Format(time.RFC3339))
+}
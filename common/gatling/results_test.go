@@ -0,0 +1,45 @@
+package gatling
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createRunDir(t *testing.T, resultsDir string, name string, simulationLog string, modTime time.Time) string {
+	runDir := filepath.Join(resultsDir, name)
+	assert.Nil(t, os.MkdirAll(runDir, 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(runDir, SIMULATION_LOG), []byte(simulationLog), 0644))
+	assert.Nil(t, os.Chtimes(runDir, modTime, modTime))
+	return runDir
+}
+
+func Test_RunDirs_OrdersRunsByStartRatherThanModificationTime(t *testing.T) {
+	resultsDir := t.TempDir()
+	now := time.Now()
+	// modified last, as when an older run is written to or copied
+	older := createRunDir(t, resultsDir, "perfizsimulation-20210801130000000", "RUN\torg.znsio.perfiz.PerfizSimulation\tperfizsimulation\t1627822800000\t \t3.5.1\n", now)
+	// a binary simulation.log of Gatling 3.7, dated by its name
+	newer := createRunDir(t, resultsDir, "perfizsimulation-20210802130000000", "\x00\x01binary", now.Add(-time.Hour))
+
+	runDirs, err := RunDirs(resultsDir)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{older, newer}, runDirs)
+	assert.Equal(t, time.Date(2021, 8, 1, 13, 0, 0, 0, time.UTC), RunStart(older).UTC())
+	assert.Equal(t, time.Date(2021, 8, 2, 13, 0, 0, 0, time.UTC), RunStart(newer).UTC())
+}
+
+func Test_LatestRunDir_ReturnsRunStartedSinceTime(t *testing.T) {
+	resultsDir := t.TempDir()
+	createRunDir(t, resultsDir, "perfizsimulation-20210801130000000", "RUN\torg.znsio.perfiz.PerfizSimulation\tperfizsimulation\t1627822800000\t \t3.5.1\n", time.Now())
+
+	runDir, err := LatestRunDir(resultsDir, time.Date(2021, 8, 1, 12, 0, 0, 0, time.UTC))
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(resultsDir, "perfizsimulation-20210801130000000"), runDir)
+	_, err = LatestRunDir(resultsDir, time.Date(2021, 8, 1, 14, 0, 0, 0, time.UTC))
+	assert.Contains(t, err.Error(), "no Gatling results found in "+resultsDir+" since ")
+}
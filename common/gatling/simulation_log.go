@@ -0,0 +1,268 @@
+package gatling
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SIMULATION_LOG = "simulation.log"
+	STATUS_OK      = "OK"
+	STATUS_KO      = "KO"
+)
+
+// SimulationLog is the content of the simulation.log Gatling writes in every run
+// directory under the results folder.
+type SimulationLog struct {
+	Run      RunRecord
+	Users    []UserRecord
+	Requests []RequestRecord
+	Groups   []GroupRecord
+	Errors   []ErrorRecord
+}
+
+type RunRecord struct {
+	SimulationClass string
+	SimulationId    string
+	Start           time.Time
+	Description     string
+	GatlingVersion  string
+}
+
+type UserRecord struct {
+	Scenario  string
+	UserId    string
+	Event     string
+	Timestamp time.Time
+}
+
+type RequestRecord struct {
+	Scenario string
+	UserId   string
+	Groups   []string
+	Name     string
+	Start    time.Time
+	End      time.Time
+	OK       bool
+	Message  string
+}
+
+type GroupRecord struct {
+	Groups                []string
+	Start                 time.Time
+	End                   time.Time
+	CumulatedResponseTime time.Duration
+	OK                    bool
+}
+
+type ErrorRecord struct {
+	Message   string
+	Timestamp time.Time
+}
+
+func (request *RequestRecord) ResponseTime() time.Duration {
+	return request.End.Sub(request.Start)
+}
+
+// FullName is the request name prefixed with the groups it was made in, which is how
+// Gatling tells apart requests with the same name.
+func (request *RequestRecord) FullName() string {
+	return strings.Join(append(append([]string{}, request.Groups...), request.Name), " / ")
+}
+
+func ParseFile(simulationLogFile string) (*SimulationLog, error) {
+	file, openErr := os.Open(simulationLogFile)
+	if openErr != nil {
+		return nil, openErr
+	}
+	defer file.Close()
+	simulationLog, parseErr := Parse(file)
+	if parseErr != nil {
+		return nil, errors.New(simulationLogFile + ": " + parseErr.Error())
+	}
+	return simulationLog, nil
+}
+
+// Parse reads the tab separated simulation.log of Gatling 3 up to 3.6. The layout of
+// USER and REQUEST records changed between those versions, so their fields are located
+// relative to the START/END and OK/KO markers rather than by fixed position.
+func Parse(reader io.Reader) (*SimulationLog, error) {
+	simulationLog := &SimulationLog{}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r\n")
+		if line == "" {
+			continue
+		}
+		if lineNumber == 1 && !strings.HasPrefix(line, "RUN\t") {
+			return nil, errors.New("not a text simulation.log. Gatling 3.7 and later write a binary simulation.log, which is not supported")
+		}
+		fields := strings.Split(line, "\t")
+		var recordErr error
+		switch fields[0] {
+		case "RUN":
+			recordErr = parseRun(fields, simulationLog)
+		case "USER":
+			recordErr = parseUser(fields, simulationLog)
+		case "REQUEST":
+			recordErr = parseRequest(fields, simulationLog)
+		case "GROUP":
+			recordErr = parseGroup(fields, simulationLog)
+		case "ERROR":
+			recordErr = parseError(fields, simulationLog)
+		default:
+			recordErr = errors.New("unknown record type " + strconv.Quote(fields[0]))
+		}
+		if recordErr != nil {
+			return nil, errors.New("line " + strconv.Itoa(lineNumber) + ": " + recordErr.Error())
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if lineNumber == 0 {
+		return nil, errors.New("empty simulation.log")
+	}
+	return simulationLog, nil
+}
+
+func parseRun(fields []string, simulationLog *SimulationLog) error {
+	if len(fields) < 4 {
+		return errors.New("RUN record has " + strconv.Itoa(len(fields)) + " fields, expected at least 4")
+	}
+	start, err := parseTimestamp(fields[3])
+	if err != nil {
+		return err
+	}
+	simulationLog.Run = RunRecord{SimulationClass: fields[1], SimulationId: fields[2], Start: start}
+	if len(fields) > 4 {
+		simulationLog.Run.Description = strings.TrimSpace(fields[4])
+	}
+	if len(fields) > 5 {
+		simulationLog.Run.GatlingVersion = fields[5]
+	}
+	return nil
+}
+
+func parseUser(fields []string, simulationLog *SimulationLog) error {
+	eventIndex := -1
+	for i := 2; i < len(fields); i++ {
+		if fields[i] == "START" || fields[i] == "END" {
+			eventIndex = i
+			break
+		}
+	}
+	if eventIndex < 0 || eventIndex+1 >= len(fields) {
+		return errors.New("USER record without START or END followed by a timestamp")
+	}
+	timestamp, err := parseTimestamp(fields[eventIndex+1])
+	if err != nil {
+		return err
+	}
+	user := UserRecord{Scenario: fields[1], Event: fields[eventIndex], Timestamp: timestamp}
+	if eventIndex == 3 {
+		user.UserId = fields[2]
+	}
+	simulationLog.Users = append(simulationLog.Users, user)
+	return nil
+}
+
+func parseRequest(fields []string, simulationLog *SimulationLog) error {
+	if isStatus(fields[len(fields)-1]) {
+		// no message after the status
+		fields = append(fields, "")
+	}
+	fieldCount := len(fields)
+	if fieldCount < 7 || !isStatus(fields[fieldCount-2]) {
+		return errors.New("REQUEST record without OK or KO status")
+	}
+	start, startErr := parseTimestamp(fields[fieldCount-4])
+	if startErr != nil {
+		return startErr
+	}
+	end, endErr := parseTimestamp(fields[fieldCount-3])
+	if endErr != nil {
+		return endErr
+	}
+	request := RequestRecord{
+		Groups:  parseGroups(fields[fieldCount-6]),
+		Name:    fields[fieldCount-5],
+		Start:   start,
+		End:     end,
+		OK:      fields[fieldCount-2] == STATUS_OK,
+		Message: strings.TrimSpace(fields[fieldCount-1]),
+	}
+	if fieldCount >= 9 {
+		request.Scenario = fields[fieldCount-8]
+		request.UserId = fields[fieldCount-7]
+	}
+	simulationLog.Requests = append(simulationLog.Requests, request)
+	return nil
+}
+
+func parseGroup(fields []string, simulationLog *SimulationLog) error {
+	if len(fields) < 6 || !isStatus(fields[len(fields)-1]) {
+		return errors.New("GROUP record without OK or KO status")
+	}
+	fieldCount := len(fields)
+	start, startErr := parseTimestamp(fields[fieldCount-4])
+	if startErr != nil {
+		return startErr
+	}
+	end, endErr := parseTimestamp(fields[fieldCount-3])
+	if endErr != nil {
+		return endErr
+	}
+	cumulatedResponseTime, cumulatedErr := strconv.ParseInt(fields[fieldCount-2], 10, 64)
+	if cumulatedErr != nil {
+		return errors.New("invalid cumulated response time " + strconv.Quote(fields[fieldCount-2]))
+	}
+	simulationLog.Groups = append(simulationLog.Groups, GroupRecord{
+		Groups:                parseGroups(fields[fieldCount-5]),
+		Start:                 start,
+		End:                   end,
+		CumulatedResponseTime: time.Duration(cumulatedResponseTime) * time.Millisecond,
+		OK:                    fields[fieldCount-1] == STATUS_OK,
+	})
+	return nil
+}
+
+func parseError(fields []string, simulationLog *SimulationLog) error {
+	if len(fields) < 3 {
+		return errors.New("ERROR record has " + strconv.Itoa(len(fields)) + " fields, expected 3")
+	}
+	timestamp, err := parseTimestamp(fields[len(fields)-1])
+	if err != nil {
+		return err
+	}
+	message := strings.Join(fields[1:len(fields)-1], "\t")
+	simulationLog.Errors = append(simulationLog.Errors, ErrorRecord{Message: message, Timestamp: timestamp})
+	return nil
+}
+
+func isStatus(field string) bool {
+	return field == STATUS_OK || field == STATUS_KO
+}
+
+func parseGroups(groups string) []string {
+	if groups == "" {
+		return nil
+	}
+	return strings.Split(groups, ",")
+}
+
+func parseTimestamp(field string) (time.Time, error) {
+	millis, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+	if err != nil {
+		return time.Time{}, errors.New("invalid timestamp " + strconv.Quote(field))
+	}
+	return time.Unix(0, millis*int64(time.Millisecond)), nil
+}
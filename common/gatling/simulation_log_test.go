@@ -0,0 +1,78 @@
+package gatling
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const gatling36SimulationLog = "RUN\torg.znsio.perfiz.PerfizSimulation\tperfizsimulation\t1633072800000\t \t3.6.1\n" +
+	"USER\tBookings\tSTART\t1633072800100\n" +
+	"REQUEST\t\tGET /bookings\t1633072800100\t1633072800200\tOK\t \n" +
+	"REQUEST\t\tGET /bookings\t1633072801000\t1633072801300\tKO\tstatus.find.is(200), but actually found 500\n" +
+	"REQUEST\tcheckout\tPOST /orders\t1633072801500\t1633072801600\tOK\t \n" +
+	"GROUP\tcheckout\t1633072801400\t1633072801600\t50\tOK\n" +
+	"ERROR\tstatus.find.is(200), but actually found 500\t1633072801300\n" +
+	"USER\tBookings\tEND\t1633072802100\n"
+
+const gatling33SimulationLog = "RUN\torg.znsio.perfiz.PerfizSimulation\tperfizsimulation\t1633072800000\t \t3.3.1\n" +
+	"USER\tBookings\t1\tSTART\t1633072800100\t1633072800100\n" +
+	"REQUEST\tBookings\t1\t\tGET /bookings\t1633072800100\t1633072800200\tOK\t \n" +
+	"USER\tBookings\t1\tEND\t1633072800100\t1633072800300\n"
+
+func Test_Parse_ReadsAllRecordTypes(t *testing.T) {
+	simulationLog, err := Parse(strings.NewReader(gatling36SimulationLog))
+	assert.Nil(t, err)
+	assert.Equal(t, "org.znsio.perfiz.PerfizSimulation", simulationLog.Run.SimulationClass)
+	assert.Equal(t, "3.6.1", simulationLog.Run.GatlingVersion)
+	assert.Len(t, simulationLog.Users, 2)
+	assert.Equal(t, "END", simulationLog.Users[1].Event)
+	assert.Len(t, simulationLog.Requests, 3)
+	assert.False(t, simulationLog.Requests[1].OK)
+	assert.Equal(t, "status.find.is(200), but actually found 500", simulationLog.Requests[1].Message)
+	assert.Equal(t, 300*time.Millisecond, simulationLog.Requests[1].ResponseTime())
+	assert.Equal(t, "checkout / POST /orders", simulationLog.Requests[2].FullName())
+	assert.Equal(t, 50*time.Millisecond, simulationLog.Groups[0].CumulatedResponseTime)
+	assert.Len(t, simulationLog.Errors, 1)
+}
+
+func Test_Parse_ReadsOlderGatlingRecordLayout(t *testing.T) {
+	simulationLog, err := Parse(strings.NewReader(gatling33SimulationLog))
+	assert.Nil(t, err)
+	assert.Equal(t, UserRecord{Scenario: "Bookings", UserId: "1", Event: "START", Timestamp: time.Unix(0, 1633072800100*int64(time.Millisecond))}, simulationLog.Users[0])
+	assert.Equal(t, "Bookings", simulationLog.Requests[0].Scenario)
+	assert.Equal(t, "1", simulationLog.Requests[0].UserId)
+	assert.Equal(t, "GET /bookings", simulationLog.Requests[0].Name)
+	assert.True(t, simulationLog.Requests[0].OK)
+}
+
+func Test_Parse_RejectsBinarySimulationLog(t *testing.T) {
+	_, err := Parse(strings.NewReader("\x00\x01\x02binary"))
+	assert.Equal(t, "not a text simulation.log. Gatling 3.7 and later write a binary simulation.log, which is not supported", err.Error())
+}
+
+func Test_Parse_ReportsLineOfMalformedRecord(t *testing.T) {
+	_, err := Parse(strings.NewReader("RUN\tSimulation\tsimulation\t1633072800000\t \t3.6.1\nREQUEST\t\tGET /\tnot-a-time\t1633072800200\tOK\t \n"))
+	assert.Equal(t, "line 2: invalid timestamp \"not-a-time\"", err.Error())
+}
+
+func Test_ComputeStatistics_ComputesPerRequestAndGlobalFigures(t *testing.T) {
+	simulationLog, _ := Parse(strings.NewReader(gatling36SimulationLog))
+	statistics := ComputeStatistics(simulationLog)
+	assert.Equal(t, RequestStatistics{
+		Name: ALL_REQUESTS, Count: 3, OK: 2, KO: 1,
+		MinResponseTime: 100, MeanResponseTime: float64(500) / 3, MaxResponseTime: 300,
+		P50ResponseTime: 100, P75ResponseTime: 300, P95ResponseTime: 300, P99ResponseTime: 300,
+		Throughput: 2,
+	}, statistics.Global)
+	bookings, found := statistics.Request("GET /bookings")
+	assert.True(t, found)
+	assert.Equal(t, 2, bookings.Count)
+	assert.Equal(t, float64(50), bookings.ErrorRate())
+	assert.Equal(t, int64(100), bookings.MinResponseTime)
+	assert.Equal(t, float64(200), bookings.MeanResponseTime)
+	assert.Equal(t, float64(4)/3, bookings.Throughput)
+	assert.Equal(t, "checkout / POST /orders", statistics.Requests[1].Name)
+}
@@ -0,0 +1,124 @@
+package gatling
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const ALL_REQUESTS = "All Requests"
+
+// RequestStatistics are the figures Gatling shows for a request in its report. Response
+// times are in milliseconds and include failed requests, as in the Gatling report.
+type RequestStatistics struct {
+	Name             string  `json:"name"`
+	Count            int     `json:"count"`
+	OK               int     `json:"ok"`
+	KO               int     `json:"ko"`
+	MinResponseTime  int64   `json:"minResponseTimeMs"`
+	MeanResponseTime float64 `json:"meanResponseTimeMs"`
+	MaxResponseTime  int64   `json:"maxResponseTimeMs"`
+	P50ResponseTime  int64   `json:"p50ResponseTimeMs"`
+	P75ResponseTime  int64   `json:"p75ResponseTimeMs"`
+	P95ResponseTime  int64   `json:"p95ResponseTimeMs"`
+	P99ResponseTime  int64   `json:"p99ResponseTimeMs"`
+	Throughput       float64 `json:"throughputPerSec"`
+}
+
+type Statistics struct {
+	Start    time.Time           `json:"start"`
+	End      time.Time           `json:"end"`
+	Global   RequestStatistics   `json:"global"`
+	Requests []RequestStatistics `json:"requests"`
+}
+
+// ErrorRate is the percentage of failed requests.
+func (statistics *RequestStatistics) ErrorRate() float64 {
+	if statistics.Count == 0 {
+		return 0
+	}
+	return float64(statistics.KO) * 100 / float64(statistics.Count)
+}
+
+// Request returns the statistics of the named request, or of all requests for ALL_REQUESTS.
+func (statistics *Statistics) Request(name string) (RequestStatistics, bool) {
+	if name == ALL_REQUESTS {
+		return statistics.Global, true
+	}
+	for _, requestStatistics := range statistics.Requests {
+		if requestStatistics.Name == name {
+			return requestStatistics, true
+		}
+	}
+	return RequestStatistics{}, false
+}
+
+// ComputeStatistics works out statistics per request and for all requests together.
+// Throughput is measured over the time from the first request sent to the last
+// response received in the run, for every request alike.
+func ComputeStatistics(simulationLog *SimulationLog) *Statistics {
+	statistics := &Statistics{}
+	var requestNames []string
+	responseTimesByName := map[string][]int64{}
+	koByName := map[string]int{}
+	var allResponseTimes []int64
+	allKO := 0
+	for i, request := range simulationLog.Requests {
+		if i == 0 || request.Start.Before(statistics.Start) {
+			statistics.Start = request.Start
+		}
+		if i == 0 || request.End.After(statistics.End) {
+			statistics.End = request.End
+		}
+		name := request.FullName()
+		if _, seen := responseTimesByName[name]; !seen {
+			requestNames = append(requestNames, name)
+		}
+		responseTime := request.ResponseTime().Milliseconds()
+		responseTimesByName[name] = append(responseTimesByName[name], responseTime)
+		allResponseTimes = append(allResponseTimes, responseTime)
+		if !request.OK {
+			koByName[name]++
+			allKO++
+		}
+	}
+	runSeconds := statistics.End.Sub(statistics.Start).Seconds()
+	statistics.Global = requestStatistics(ALL_REQUESTS, allResponseTimes, allKO, runSeconds)
+	for _, name := range requestNames {
+		statistics.Requests = append(statistics.Requests, requestStatistics(name, responseTimesByName[name], koByName[name], runSeconds))
+	}
+	return statistics
+}
+
+func requestStatistics(name string, responseTimes []int64, ko int, runSeconds float64) RequestStatistics {
+	statistics := RequestStatistics{Name: name, Count: len(responseTimes), OK: len(responseTimes) - ko, KO: ko}
+	if len(responseTimes) == 0 {
+		return statistics
+	}
+	sorted := append([]int64{}, responseTimes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var total int64
+	for _, responseTime := range sorted {
+		total += responseTime
+	}
+	statistics.MinResponseTime = sorted[0]
+	statistics.MaxResponseTime = sorted[len(sorted)-1]
+	statistics.MeanResponseTime = float64(total) / float64(len(sorted))
+	statistics.P50ResponseTime = percentile(sorted, 50)
+	statistics.P75ResponseTime = percentile(sorted, 75)
+	statistics.P95ResponseTime = percentile(sorted, 95)
+	statistics.P99ResponseTime = percentile(sorted, 99)
+	if runSeconds > 0 {
+		statistics.Throughput = float64(len(sorted)) / runSeconds
+	}
+	return statistics
+}
+
+// percentile uses the nearest rank method on sorted response times.
+func percentile(sorted []int64, rank float64) int64 {
+	index := int(math.Ceil(rank/100*float64(len(sorted)))) - 1
+	if index < 0 {
+		index = 0
+	}
+	return sorted[index]
+}
@@ -0,0 +1,95 @@
+package health
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Check is the endpoint that tells whether a service is ready. A service is ready when
+// its endpoint answers with a 2xx status.
+type Check struct {
+	Service string
+	Url     string
+}
+
+// Poller checks endpoints every Interval until they are ready or Timeout has passed.
+type Poller struct {
+	Client   *http.Client
+	Interval time.Duration
+	Timeout  time.Duration
+	// OnReady is called once for every service that becomes ready.
+	OnReady func(check Check, after time.Duration)
+}
+
+func NewPoller(timeout time.Duration) *Poller {
+	return &Poller{
+		Client:   &http.Client{Timeout: 5 * time.Second},
+		Interval: time.Second,
+		Timeout:  timeout,
+		OnReady:  func(Check, time.Duration) {},
+	}
+}
+
+// WaitFor polls all checks at the same time and returns an error naming the services
+// that were not ready in time, along with why.
+func (poller *Poller) WaitFor(checks []Check) error {
+	start := time.Now()
+	deadline := start.Add(poller.Timeout)
+	var mutex sync.Mutex
+	var waitGroup sync.WaitGroup
+	notReady := map[string]string{}
+	for _, check := range checks {
+		waitGroup.Add(1)
+		go func(check Check) {
+			defer waitGroup.Done()
+			for {
+				reason := poller.probe(check)
+				mutex.Lock()
+				if reason == "" {
+					poller.OnReady(check, time.Since(start))
+					mutex.Unlock()
+					return
+				}
+				if !time.Now().Add(poller.Interval).Before(deadline) {
+					notReady[check.Service] = reason
+					mutex.Unlock()
+					return
+				}
+				mutex.Unlock()
+				time.Sleep(poller.Interval)
+			}
+		}(check)
+	}
+	waitGroup.Wait()
+	if len(notReady) == 0 {
+		return nil
+	}
+	var services []string
+	for service := range notReady {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+	var reasons []string
+	for _, service := range services {
+		reasons = append(reasons, service+" ("+notReady[service]+")")
+	}
+	return errors.New("not ready after " + poller.Timeout.String() + ": " + strings.Join(reasons, ", "))
+}
+
+// probe returns why a service is not ready, or "" when it is.
+func (poller *Poller) probe(check Check) string {
+	response, err := poller.Client.Get(check.Url)
+	if err != nil {
+		return err.Error()
+	}
+	response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return check.Url + " answered " + strconv.Itoa(response.StatusCode)
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package health
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_WaitFor_ReturnsOnceAllServicesAreReady(t *testing.T) {
+	var probes int32
+	grafana := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if atomic.AddInt32(&probes, 1) < 3 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		writer.Write([]byte(`{"database": "ok"}`))
+	}))
+	defer grafana.Close()
+	influxdb := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusNoContent)
+	}))
+	defer influxdb.Close()
+
+	poller := NewPoller(5 * time.Second)
+	poller.Interval = 10 * time.Millisecond
+	var ready []string
+	poller.OnReady = func(check Check, after time.Duration) { ready = append(ready, check.Service) }
+
+	err := poller.WaitFor([]Check{{"grafana", grafana.URL + "/api/health"}, {"influxdb", influxdb.URL + "/ping"}})
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"grafana", "influxdb"}, ready)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&probes))
+}
+
+func Test_WaitFor_NamesServicesThatDidNotComeUp(t *testing.T) {
+	prometheus := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer prometheus.Close()
+
+	poller := NewPoller(50 * time.Millisecond)
+	poller.Interval = 10 * time.Millisecond
+	err := poller.WaitFor([]Check{{"prometheus", prometheus.URL + "/-/ready"}})
+	assert.Equal(t, "not ready after 50ms: prometheus ("+prometheus.URL+"/-/ready answered 503)", err.Error())
+}
@@ -0,0 +1,150 @@
+package history
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/znsio/perfiz-cli/common/configuration"
+	"github.com/znsio/perfiz-cli/common/constants"
+)
+
+const (
+	OUTCOME_PASSED               = "passed"
+	OUTCOME_CONFIG_ERROR         = "config error"
+	OUTCOME_INFRASTRUCTURE_ERROR = "infrastructure error"
+	OUTCOME_TEST_FAILED          = "test failed"
+	OUTCOME_CONTAINER_KILLED     = "container killed"
+	OUTCOME_ASSERTIONS_FAILED    = "assertions failed"
+	OUTCOME_ERROR                = "error"
+)
+
+// Entry records a run of perfiz test in the history file, one JSON object per line.
+// The metrics are those of all requests together and are zero when the run left no
+// results behind.
+type Entry struct {
+	RunId            string    `json:"runId,omitempty"`
+	Start            time.Time `json:"start"`
+	End              time.Time `json:"end"`
+	ConfigFile       string    `json:"configFile"`
+	ConfigHash       string    `json:"configHash"`
+	Profile          string    `json:"profile,omitempty"`
+	KarateEnv        string    `json:"karateEnv,omitempty"`
+	GitCommit        string    `json:"gitCommit,omitempty"`
+	Tags             []string  `json:"tags,omitempty"`
+	ExitCode         int       `json:"exitCode"`
+	Outcome          string    `json:"outcome"`
+	Requests         int       `json:"requests"`
+	ErrorRate        float64   `json:"errorRatePercent"`
+	MeanResponseTime float64   `json:"meanResponseTimeMs"`
+	P95ResponseTime  int64     `json:"p95ResponseTimeMs"`
+	Throughput       float64   `json:"throughputPerSec"`
+}
+
+// Filter selects entries of the history. Empty fields match every entry.
+type Filter struct {
+	ConfigFile string
+	ConfigHash string
+	GitCommit  string
+	Outcome    string
+	Profile    string
+	Since      time.Time
+	Last       int
+}
+
+// ConfigHash identifies a config as resolved for a run, so that runs of the same load
+// can be found whichever file, profile or overrides it came from.
+func ConfigHash(perfizConfig *configuration.PerfizConfig) string {
+	configJson, _ := json.Marshal(perfizConfig)
+	hash := sha256.Sum256(configJson)
+	return hex.EncodeToString(hash[:])[:12]
+}
+
+func Outcome(exitCode int) string {
+	switch exitCode {
+	case 0:
+		return OUTCOME_PASSED
+	case constants.EXIT_CODE_CONFIG_ERROR:
+		return OUTCOME_CONFIG_ERROR
+	case constants.EXIT_CODE_INFRASTRUCTURE_ERROR:
+		return OUTCOME_INFRASTRUCTURE_ERROR
+	case constants.EXIT_CODE_TEST_FAILED:
+		return OUTCOME_TEST_FAILED
+	case constants.EXIT_CODE_CONTAINER_KILLED:
+		return OUTCOME_CONTAINER_KILLED
+	case constants.EXIT_CODE_ASSERTIONS_FAILED:
+		return OUTCOME_ASSERTIONS_FAILED
+	}
+	return OUTCOME_ERROR
+}
+
+// Append adds an entry to the end of the history file, creating it if need be.
+func Append(historyFile string, entry Entry) error {
+	entryJson, marshalErr := json.Marshal(entry)
+	if marshalErr != nil {
+		return marshalErr
+	}
+	file, openErr := os.OpenFile(historyFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if openErr != nil {
+		return openErr
+	}
+	if _, writeErr := file.Write(append(entryJson, '\n')); writeErr != nil {
+		file.Close()
+		return writeErr
+	}
+	return file.Close()
+}
+
+// Read returns the entries of the history file, oldest first. A missing history file
+// is an empty history.
+func Read(historyFile string) ([]Entry, error) {
+	file, openErr := os.Open(historyFile)
+	if os.IsNotExist(openErr) {
+		return nil, nil
+	}
+	if openErr != nil {
+		return nil, openErr
+	}
+	defer file.Close()
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, errors.New(historyFile + ": line " + strconv.Itoa(lineNumber) + ": " + err.Error())
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// Apply returns the entries that match the filter, keeping only the Last ones when set.
+func (filter Filter) Apply(entries []Entry) []Entry {
+	var matching []Entry
+	for _, entry := range entries {
+		if (filter.ConfigFile != "" && entry.ConfigFile != filter.ConfigFile) ||
+			(filter.ConfigHash != "" && entry.ConfigHash != filter.ConfigHash) ||
+			(filter.GitCommit != "" && !strings.HasPrefix(entry.GitCommit, filter.GitCommit)) ||
+			(filter.Outcome != "" && entry.Outcome != filter.Outcome) ||
+			(filter.Profile != "" && entry.Profile != filter.Profile) ||
+			(!filter.Since.IsZero() && entry.Start.Before(filter.Since)) {
+			continue
+		}
+		matching = append(matching, entry)
+	}
+	if filter.Last > 0 && len(matching) > filter.Last {
+		matching = matching[len(matching)-filter.Last:]
+	}
+	return matching
+}
@@ -0,0 +1,70 @@
+package history
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/znsio/perfiz-cli/common/configuration"
+	"github.com/znsio/perfiz-cli/common/constants"
+)
+
+var start = time.Date(2021, 9, 1, 10, 0, 0, 0, time.UTC)
+
+var entries = []Entry{
+	{RunId: "perfizsimulation-20210901100000", Start: start, ConfigFile: "perfiz.yml", ConfigHash: "a1b2c3d4e5f6", GitCommit: "3967b7d0c5f1", Outcome: OUTCOME_PASSED, Requests: 100, P95ResponseTime: 200, Throughput: 50},
+	{Start: start.Add(time.Hour), ConfigFile: "perfiz.yml", ConfigHash: "a1b2c3d4e5f6", GitCommit: "3967b7d0c5f1", Outcome: OUTCOME_INFRASTRUCTURE_ERROR},
+	{RunId: "perfizsimulation-20210901120000", Start: start.Add(2 * time.Hour), ConfigFile: "soak.yml", ConfigHash: "f6e5d4c3b2a1", GitCommit: "8a1c3e5f7a9b", Profile: "soak", Outcome: OUTCOME_ASSERTIONS_FAILED, Requests: 100, P95ResponseTime: 300, Throughput: 40},
+	{RunId: "perfizsimulation-20210901130000", Start: start.Add(3 * time.Hour), ConfigFile: "perfiz.yml", ConfigHash: "a1b2c3d4e5f6", GitCommit: "8a1c3e5f7a9b", Outcome: OUTCOME_PASSED, Requests: 100, P95ResponseTime: 250, Throughput: 45},
+}
+
+func Test_Append_AddsEntriesThatReadReturnsInOrder(t *testing.T) {
+	historyFile := filepath.Join(t.TempDir(), "history.jsonl")
+	for _, entry := range entries {
+		assert.Nil(t, Append(historyFile, entry))
+	}
+	readEntries, err := Read(historyFile)
+	assert.Nil(t, err)
+	assert.Equal(t, len(entries), len(readEntries))
+	assert.Equal(t, entries[2].Profile, readEntries[2].Profile)
+	assert.True(t, readEntries[3].Start.Equal(entries[3].Start))
+}
+
+func Test_Read_ReturnsEmptyHistoryWhenFileIsMissing(t *testing.T) {
+	readEntries, err := Read(filepath.Join(t.TempDir(), "history.jsonl"))
+	assert.Nil(t, err)
+	assert.Empty(t, readEntries)
+}
+
+func Test_Filter_SelectsMatchingEntries(t *testing.T) {
+	assert.Equal(t, []Entry{entries[0], entries[1], entries[3]}, Filter{ConfigHash: "a1b2c3d4e5f6"}.Apply(entries))
+	assert.Equal(t, []Entry{entries[2], entries[3]}, Filter{GitCommit: "8a1c"}.Apply(entries))
+	assert.Equal(t, []Entry{entries[0], entries[3]}, Filter{Outcome: OUTCOME_PASSED}.Apply(entries))
+	assert.Equal(t, []Entry{entries[2], entries[3]}, Filter{Since: start.Add(90 * time.Minute)}.Apply(entries))
+	assert.Equal(t, []Entry{entries[3]}, Filter{ConfigFile: "perfiz.yml", Last: 1}.Apply(entries))
+}
+
+func Test_ConfigHash_DependsOnResolvedConfigOnly(t *testing.T) {
+	config := &configuration.PerfizConfig{KarateFeaturesDir: "karate-features", KarateEnv: "staging"}
+	withProfiles := &configuration.PerfizConfig{KarateFeaturesDir: "karate-features", KarateEnv: "staging",
+		Profiles: map[string]configuration.Profile{"soak": {KarateEnv: "soak"}}}
+	otherEnv := &configuration.PerfizConfig{KarateFeaturesDir: "karate-features", KarateEnv: "production"}
+	assert.Equal(t, ConfigHash(config), ConfigHash(withProfiles))
+	assert.NotEqual(t, ConfigHash(config), ConfigHash(otherEnv))
+	assert.Len(t, ConfigHash(config), 12)
+}
+
+func Test_Outcome_DescribesExitCode(t *testing.T) {
+	assert.Equal(t, OUTCOME_PASSED, Outcome(0))
+	assert.Equal(t, OUTCOME_ASSERTIONS_FAILED, Outcome(constants.EXIT_CODE_ASSERTIONS_FAILED))
+	assert.Equal(t, OUTCOME_ERROR, Outcome(42))
+}
+
+func Test_WriteTrends_ShowsMetricsOfRunsWithResults(t *testing.T) {
+	var trends bytes.Buffer
+	WriteTrends(&trends, entries)
+	assert.Contains(t, trends.String(), "p95ResponseTime   200.00  250.00  200.00  300.00  25.0%   ▁█▄\n")
+	assert.Contains(t, trends.String(), "throughput        50.00   45.00   40.00   50.00   -10.0%  █▁▄\n")
+}
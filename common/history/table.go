@@ -0,0 +1,108 @@
+package history
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+)
+
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// trendMetric is a headline metric of the history for which a trend is shown.
+type trendMetric struct {
+	name  string
+	value func(entry Entry) float64
+}
+
+var trendMetrics = []trendMetric{
+	{"errorRate", func(entry Entry) float64 { return entry.ErrorRate }},
+	{"meanResponseTime", func(entry Entry) float64 { return entry.MeanResponseTime }},
+	{"p95ResponseTime", func(entry Entry) float64 { return float64(entry.P95ResponseTime) }},
+	{"throughput", func(entry Entry) float64 { return entry.Throughput }},
+}
+
+func WriteTable(writer io.Writer, entries []Entry) {
+	table := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "START\tRUN\tOUTCOME\tCOMMIT\tCONFIG\tREQUESTS\tERRORS\tP95\tTHROUGHPUT")
+	for _, entry := range entries {
+		config := entry.ConfigFile + " " + entry.ConfigHash
+		if entry.Profile != "" {
+			config += " (" + entry.Profile + ")"
+		}
+		fmt.Fprintln(table, entry.Start.Local().Format("2006-01-02 15:04:05")+"\t"+orDash(entry.RunId)+"\t"+entry.Outcome+"\t"+
+			orDash(shortCommit(entry.GitCommit))+"\t"+config+"\t"+strconv.Itoa(entry.Requests)+"\t"+
+			strconv.FormatFloat(entry.ErrorRate, 'f', 2, 64)+"%\t"+strconv.FormatInt(entry.P95ResponseTime, 10)+"ms\t"+
+			strconv.FormatFloat(entry.Throughput, 'f', 2, 64)+"/s")
+	}
+	table.Flush()
+}
+
+// WriteTrends shows how the headline metrics developed over the runs that produced
+// results, from the first to the last of them.
+func WriteTrends(writer io.Writer, entries []Entry) {
+	var withResults []Entry
+	for _, entry := range entries {
+		if entry.Requests > 0 {
+			withResults = append(withResults, entry)
+		}
+	}
+	if len(withResults) == 0 {
+		fmt.Fprintln(writer, "No runs with results to show trends for.")
+		return
+	}
+	table := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "METRIC\tFIRST\tLAST\tMIN\tMAX\tCHANGE\tTREND")
+	for _, metric := range trendMetrics {
+		var values []float64
+		for _, entry := range withResults {
+			values = append(values, metric.value(entry))
+		}
+		min, max := values[0], values[0]
+		for _, value := range values {
+			if value < min {
+				min = value
+			}
+			if value > max {
+				max = value
+			}
+		}
+		first, last := values[0], values[len(values)-1]
+		change := "n/a"
+		if first != 0 {
+			change = strconv.FormatFloat((last-first)*100/first, 'f', 1, 64) + "%"
+		}
+		fmt.Fprintln(table, metric.name+"\t"+format(first)+"\t"+format(last)+"\t"+format(min)+"\t"+format(max)+"\t"+change+"\t"+sparkline(values, min, max))
+	}
+	table.Flush()
+}
+
+func sparkline(values []float64, min float64, max float64) string {
+	var line []rune
+	for _, value := range values {
+		spark := 0
+		if max > min {
+			spark = int((value - min) / (max - min) * float64(len(sparks)-1))
+		}
+		line = append(line, sparks[spark])
+	}
+	return string(line)
+}
+
+func format(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 8 {
+		return commit[:8]
+	}
+	return commit
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -3,6 +3,7 @@ package path
 import (
 	"os"
 	"path/filepath"
+	"strconv"
 )
 
 func IsDir(pathFile string) bool {
@@ -14,3 +15,31 @@ func IsDir(pathFile string) bool {
 
 	return true
 }
+
+// Size is the total size of the files in a directory and its subdirectories, or of
+// a single file.
+func Size(pathFile string) int64 {
+	var size int64
+	filepath.Walk(pathFile, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// HumanSize formats a size in bytes like "12.3 MB".
+func HumanSize(size int64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	value := float64(size)
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return strconv.FormatInt(size, 10) + " B"
+	}
+	return strconv.FormatFloat(value, 'f', 1, 64) + " " + units[unit]
+}
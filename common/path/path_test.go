@@ -0,0 +1,25 @@
+package path
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Size_AddsUpFilesInSubdirectories(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "js"), 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "index.html"), make([]byte, 100), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "js", "stats.js"), make([]byte, 50), 0644))
+	assert.Equal(t, int64(150), Size(dir))
+	assert.Equal(t, int64(0), Size(filepath.Join(dir, "missing")))
+}
+
+func Test_HumanSize_UsesLargestFittingUnit(t *testing.T) {
+	assert.Equal(t, "512 B", HumanSize(512))
+	assert.Equal(t, "1.5 KB", HumanSize(1536))
+	assert.Equal(t, "2.0 GB", HumanSize(2*1024*1024*1024))
+}
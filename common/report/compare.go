@@ -0,0 +1,134 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/znsio/perfiz-cli/common/configuration"
+	"github.com/znsio/perfiz-cli/common/gatling"
+	"github.com/znsio/perfiz-cli/common/sla"
+)
+
+var ComparedMetrics = []configuration.AssertionMetric{
+	configuration.P50_RESPONSE_TIME,
+	configuration.P95_RESPONSE_TIME,
+	configuration.P99_RESPONSE_TIME,
+	configuration.THROUGHPUT,
+	configuration.ERROR_RATE,
+}
+
+// Tolerances are how much worse a run may be than its baseline before it counts as a
+// regression. Response times and throughput are in percent of the baseline, the error
+// rate in percentage points, as a baseline without errors has no percentage to grow by.
+type Tolerances struct {
+	ResponseTime float64
+	Throughput   float64
+	ErrorRate    float64
+}
+
+// Delta is how a metric of a request changed from the baseline run to the candidate.
+type Delta struct {
+	Request   string
+	Metric    configuration.AssertionMetric
+	Baseline  float64
+	Candidate float64
+	Regressed bool
+}
+
+// Comparison of two runs. Requests made in only one of them can not be compared and
+// are listed by name.
+type Comparison struct {
+	Deltas          []Delta
+	OnlyInBaseline  []string
+	OnlyInCandidate []string
+	Regressions     int
+}
+
+// Compare works out the deltas of all requests together and of every request made in
+// both runs, in the order of the candidate run.
+func Compare(baseline *gatling.Statistics, candidate *gatling.Statistics, tolerances Tolerances) *Comparison {
+	comparison := &Comparison{}
+	candidateRequests := append([]gatling.RequestStatistics{candidate.Global}, candidate.Requests...)
+	for _, candidateRequest := range candidateRequests {
+		baselineRequest, found := baseline.Request(candidateRequest.Name)
+		if !found {
+			comparison.OnlyInCandidate = append(comparison.OnlyInCandidate, candidateRequest.Name)
+			continue
+		}
+		for _, metric := range ComparedMetrics {
+			delta := Delta{
+				Request:   candidateRequest.Name,
+				Metric:    metric,
+				Baseline:  sla.MetricValue(metric, baselineRequest),
+				Candidate: sla.MetricValue(metric, candidateRequest),
+			}
+			delta.Regressed = delta.exceeds(tolerances)
+			if delta.Regressed {
+				comparison.Regressions++
+			}
+			comparison.Deltas = append(comparison.Deltas, delta)
+		}
+	}
+	for _, baselineRequest := range baseline.Requests {
+		if _, found := candidate.Request(baselineRequest.Name); !found {
+			comparison.OnlyInBaseline = append(comparison.OnlyInBaseline, baselineRequest.Name)
+		}
+	}
+	return comparison
+}
+
+func (delta Delta) exceeds(tolerances Tolerances) bool {
+	switch delta.Metric {
+	case configuration.ERROR_RATE:
+		return delta.Candidate-delta.Baseline > tolerances.ErrorRate
+	case configuration.THROUGHPUT:
+		return delta.Candidate < delta.Baseline*(1-tolerances.Throughput/100)
+	default:
+		return delta.Candidate > delta.Baseline*(1+tolerances.ResponseTime/100)
+	}
+}
+
+// Change is the difference to the baseline, in percentage points for the error rate and
+// in percent of the baseline for the other metrics.
+func (delta Delta) Change() string {
+	if delta.Metric == configuration.ERROR_RATE {
+		return signed(delta.Candidate-delta.Baseline) + " pts"
+	}
+	if delta.Baseline == 0 {
+		if delta.Candidate == 0 {
+			return signed(0) + "%"
+		}
+		return "n/a"
+	}
+	return signed((delta.Candidate-delta.Baseline)*100/delta.Baseline) + "%"
+}
+
+func (comparison *Comparison) WriteTable(writer io.Writer) {
+	table := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "REQUEST\tMETRIC\tBASELINE\tCANDIDATE\tCHANGE\tRESULT")
+	for _, delta := range comparison.Deltas {
+		outcome := "OK"
+		if delta.Regressed {
+			outcome = "REGRESSED"
+		}
+		fmt.Fprintln(table, delta.Request+"\t"+string(delta.Metric)+"\t"+
+			strconv.FormatFloat(delta.Baseline, 'f', 2, 64)+"\t"+strconv.FormatFloat(delta.Candidate, 'f', 2, 64)+"\t"+
+			delta.Change()+"\t"+outcome)
+	}
+	table.Flush()
+	for _, name := range comparison.OnlyInBaseline {
+		fmt.Fprintln(writer, "Only in baseline: "+name)
+	}
+	for _, name := range comparison.OnlyInCandidate {
+		fmt.Fprintln(writer, "Only in candidate: "+name)
+	}
+}
+
+func signed(value float64) string {
+	if value >= 0 {
+		return "+" + strconv.FormatFloat(value, 'f', 2, 64)
+	}
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
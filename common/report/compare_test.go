@@ -0,0 +1,51 @@
+package report
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/znsio/perfiz-cli/common/configuration"
+	"github.com/znsio/perfiz-cli/common/gatling"
+)
+
+var tolerances = Tolerances{ResponseTime: 10, Throughput: 10, ErrorRate: 1}
+
+func Test_Compare_FlagsRegressionsBeyondTolerances(t *testing.T) {
+	baseline := &gatling.Statistics{
+		Global:   gatling.RequestStatistics{Name: gatling.ALL_REQUESTS, Count: 100, P50ResponseTime: 100, P95ResponseTime: 200, P99ResponseTime: 300, Throughput: 50},
+		Requests: []gatling.RequestStatistics{{Name: "GET /bookings", Count: 100, P50ResponseTime: 100, P95ResponseTime: 200, P99ResponseTime: 300, Throughput: 50}},
+	}
+	candidate := &gatling.Statistics{
+		Global:   gatling.RequestStatistics{Name: gatling.ALL_REQUESTS, Count: 100, KO: 2, P50ResponseTime: 105, P95ResponseTime: 250, P99ResponseTime: 300, Throughput: 44},
+		Requests: []gatling.RequestStatistics{{Name: "GET /bookings", Count: 100, KO: 2, P50ResponseTime: 105, P95ResponseTime: 250, P99ResponseTime: 300, Throughput: 44}},
+	}
+	comparison := Compare(baseline, candidate, tolerances)
+
+	var regressed []configuration.AssertionMetric
+	for _, delta := range comparison.Deltas[:len(ComparedMetrics)] {
+		if delta.Regressed {
+			regressed = append(regressed, delta.Metric)
+		}
+	}
+	assert.Equal(t, []configuration.AssertionMetric{configuration.P95_RESPONSE_TIME, configuration.THROUGHPUT, configuration.ERROR_RATE}, regressed)
+	assert.Equal(t, 6, comparison.Regressions)
+	assert.Equal(t, "+25.00%", comparison.Deltas[1].Change())
+	assert.Equal(t, "+2.00 pts", comparison.Deltas[4].Change())
+}
+
+func Test_Compare_ListsRequestsMadeInOnlyOneRun(t *testing.T) {
+	global := gatling.RequestStatistics{Name: gatling.ALL_REQUESTS}
+	baseline := &gatling.Statistics{Global: global, Requests: []gatling.RequestStatistics{{Name: "GET /bookings"}, {Name: "DELETE /bookings"}}}
+	candidate := &gatling.Statistics{Global: global, Requests: []gatling.RequestStatistics{{Name: "GET /bookings"}, {Name: "POST /bookings"}}}
+	comparison := Compare(baseline, candidate, tolerances)
+
+	assert.Equal(t, []string{"DELETE /bookings"}, comparison.OnlyInBaseline)
+	assert.Equal(t, []string{"POST /bookings"}, comparison.OnlyInCandidate)
+	assert.Equal(t, 0, comparison.Regressions)
+
+	var table bytes.Buffer
+	comparison.WriteTable(&table)
+	assert.Contains(t, table.String(), "GET /bookings  p95ResponseTime  0.00      0.00       +0.00%     OK\n")
+	assert.Contains(t, table.String(), "Only in baseline: DELETE /bookings\nOnly in candidate: POST /bookings\n")
+}
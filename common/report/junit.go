@@ -0,0 +1,104 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+
+	"github.com/znsio/perfiz-cli/common/gatling"
+	"github.com/znsio/perfiz-cli/common/sla"
+)
+
+const JUNIT_REPORT = "junit.xml"
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the results of a test run as a JUnit XML report, with a test case
+// per request and one per assertion. The time of a request is its mean response time.
+// Failed requests fail their test case only when there are no assertions, since
+// assertions like an error rate limit decide how many failed requests are acceptable.
+func WriteJUnit(junitFile string, simulation string, statistics *gatling.Statistics, results []sla.Result) error {
+	requestsSuite := junitTestSuite{Name: simulation + " requests", Timestamp: statistics.Start.UTC().Format("2006-01-02T15:04:05")}
+	for _, request := range statistics.Requests {
+		testCase := junitTestCase{
+			Name:      request.Name,
+			Classname: simulation,
+			Time:      seconds(request.MeanResponseTime),
+			SystemOut: fmt.Sprintf("count=%d ok=%d ko=%d min=%dms mean=%.0fms max=%dms p50=%dms p75=%dms p95=%dms p99=%dms throughput=%.2f/s",
+				request.Count, request.OK, request.KO, request.MinResponseTime, request.MeanResponseTime, request.MaxResponseTime,
+				request.P50ResponseTime, request.P75ResponseTime, request.P95ResponseTime, request.P99ResponseTime, request.Throughput),
+		}
+		if request.KO > 0 && len(results) == 0 {
+			message := strconv.Itoa(request.KO) + " of " + strconv.Itoa(request.Count) + " requests failed"
+			testCase.Failure = &junitFailure{Message: message, Type: "RequestFailure", Text: message}
+			requestsSuite.Failures++
+		}
+		requestsSuite.Cases = append(requestsSuite.Cases, testCase)
+	}
+	requestsSuite.Tests = len(requestsSuite.Cases)
+	requestsSuite.Time = seconds(float64(statistics.End.Sub(statistics.Start).Milliseconds()))
+
+	testSuites := junitTestSuites{Name: simulation, Time: requestsSuite.Time, Suites: []junitTestSuite{requestsSuite}}
+	if len(results) > 0 {
+		assertionsSuite := junitTestSuite{Name: simulation + " assertions", Time: "0"}
+		for _, result := range results {
+			testCase := junitTestCase{Name: result.Assertion.String(), Classname: simulation, Time: "0"}
+			if !result.Passed {
+				message := result.Message
+				if message == "" {
+					message = "actual " + strconv.FormatFloat(result.Actual, 'f', 2, 64)
+				}
+				testCase.Failure = &junitFailure{Message: message, Type: "AssertionFailure", Text: result.Assertion.String() + ": " + message}
+				assertionsSuite.Failures++
+			}
+			assertionsSuite.Cases = append(assertionsSuite.Cases, testCase)
+		}
+		assertionsSuite.Tests = len(assertionsSuite.Cases)
+		testSuites.Suites = append(testSuites.Suites, assertionsSuite)
+	}
+	for _, suite := range testSuites.Suites {
+		testSuites.Tests += suite.Tests
+		testSuites.Failures += suite.Failures
+	}
+
+	junitXml, err := xml.MarshalIndent(testSuites, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(junitFile, append([]byte(xml.Header), append(junitXml, '\n')...), 0644)
+}
+
+func seconds(milliseconds float64) string {
+	return strconv.FormatFloat(milliseconds/1000, 'f', 3, 64)
+}
@@ -0,0 +1,71 @@
+package report
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/znsio/perfiz-cli/common/configuration"
+	"github.com/znsio/perfiz-cli/common/gatling"
+	"github.com/znsio/perfiz-cli/common/sla"
+)
+
+func threshold(value float64) *float64 {
+	return &value
+}
+
+var statistics = &gatling.Statistics{
+	Start:  time.Unix(1600000000, 0),
+	End:    time.Unix(1600000060, 0),
+	Global: gatling.RequestStatistics{Name: gatling.ALL_REQUESTS, Count: 200, OK: 198, KO: 2, MeanResponseTime: 250, P95ResponseTime: 450},
+	Requests: []gatling.RequestStatistics{
+		{Name: "GET /bookings", Count: 100, OK: 100, MeanResponseTime: 120},
+		{Name: "POST /bookings", Count: 100, OK: 98, KO: 2, MeanResponseTime: 380},
+	},
+}
+
+func readJUnit(t *testing.T, junitFile string) junitTestSuites {
+	junitXml, readErr := ioutil.ReadFile(junitFile)
+	assert.Nil(t, readErr)
+	var testSuites junitTestSuites
+	assert.Nil(t, xml.Unmarshal(junitXml, &testSuites))
+	return testSuites
+}
+
+func Test_WriteJUnit_FailsRequestsWithErrorsWhenThereAreNoAssertions(t *testing.T) {
+	junitFile := filepath.Join(t.TempDir(), JUNIT_REPORT)
+	assert.Nil(t, WriteJUnit(junitFile, "perfiz.BookingSimulation", statistics, nil))
+
+	testSuites := readJUnit(t, junitFile)
+	assert.Equal(t, 2, testSuites.Tests)
+	assert.Equal(t, 1, testSuites.Failures)
+	assert.Equal(t, 1, len(testSuites.Suites))
+	requests := testSuites.Suites[0].Cases
+	assert.Equal(t, "GET /bookings", requests[0].Name)
+	assert.Equal(t, "0.120", requests[0].Time)
+	assert.Nil(t, requests[0].Failure)
+	assert.Equal(t, "2 of 100 requests failed", requests[1].Failure.Message)
+	assert.Equal(t, "60.000", testSuites.Suites[0].Time)
+}
+
+func Test_WriteJUnit_AddsTestCasePerAssertion(t *testing.T) {
+	results := sla.Evaluate([]configuration.Assertion{
+		{Metric: configuration.P95_RESPONSE_TIME, LessThan: threshold(500)},
+		{Metric: configuration.ERROR_RATE, LessThan: threshold(0.5)},
+	}, statistics)
+	junitFile := filepath.Join(t.TempDir(), JUNIT_REPORT)
+	assert.Nil(t, WriteJUnit(junitFile, "perfiz.BookingSimulation", statistics, results))
+
+	testSuites := readJUnit(t, junitFile)
+	assert.Equal(t, 4, testSuites.Tests)
+	assert.Equal(t, 1, testSuites.Failures)
+	assert.Equal(t, 0, testSuites.Suites[0].Failures)
+	assertions := testSuites.Suites[1].Cases
+	assert.Equal(t, "p95ResponseTime of all requests < 500", assertions[0].Name)
+	assert.Nil(t, assertions[0].Failure)
+	assert.Equal(t, "errorRate of all requests < 0.5", assertions[1].Name)
+	assert.Equal(t, "actual 1.00", assertions[1].Failure.Message)
+}
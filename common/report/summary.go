@@ -0,0 +1,103 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/znsio/perfiz-cli/common/configuration"
+	"github.com/znsio/perfiz-cli/common/gatling"
+	"github.com/znsio/perfiz-cli/common/sla"
+)
+
+const (
+	SUMMARY_REPORT = "summary.json"
+	// SUMMARY_FORMAT_VERSION changes only when a field of Summary is renamed or removed
+	// or changes meaning, so that consumers can rely on the format.
+	SUMMARY_FORMAT_VERSION = 1
+)
+
+// Summary is the outcome of a test run in a stable, machine readable form. The run id
+// is the name of the run directory Gatling wrote the results to.
+type Summary struct {
+	FormatVersion     int                         `json:"formatVersion"`
+	RunId             string                      `json:"runId"`
+	ConfigFile        string                      `json:"configFile"`
+	ResolvedConfig    *configuration.PerfizConfig `json:"resolvedConfig"`
+	KarateEnv         string                      `json:"karateEnv"`
+	SimulationClass   string                      `json:"simulationClass"`
+	Start             time.Time                   `json:"start"`
+	End               time.Time                   `json:"end"`
+	CliVersion        string                      `json:"cliVersion"`
+	PerfizVersion     string                      `json:"perfizVersion"`
+	ContainerExitCode int                         `json:"containerExitCode"`
+	ExitCode          int                         `json:"exitCode"`
+	Statistics        *gatling.Statistics         `json:"statistics"`
+	Assertions        []sla.Result                `json:"assertions"`
+	Tags              []string                    `json:"tags,omitempty"`
+}
+
+func WriteSummary(summaryFile string, summary *Summary) error {
+	summary.FormatVersion = SUMMARY_FORMAT_VERSION
+	if summary.Assertions == nil {
+		summary.Assertions = []sla.Result{}
+	}
+	summaryJson, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(summaryFile, append(summaryJson, '\n'), 0644)
+}
+
+// ReadSummary reads a summary file, or the summary.json in a run directory.
+func ReadSummary(summaryFile string) (*Summary, error) {
+	if info, statErr := os.Stat(summaryFile); statErr == nil && info.IsDir() {
+		summaryFile = filepath.Join(summaryFile, SUMMARY_REPORT)
+	}
+	summaryJson, readErr := ioutil.ReadFile(summaryFile)
+	if readErr != nil {
+		return nil, readErr
+	}
+	summary := &Summary{}
+	if err := json.Unmarshal(summaryJson, summary); err != nil {
+		return nil, errors.New(summaryFile + ": " + err.Error())
+	}
+	if summary.FormatVersion > SUMMARY_FORMAT_VERSION {
+		return nil, errors.New(summaryFile + ": summary format version " + strconv.Itoa(summary.FormatVersion) + " is newer than this perfiz-cli understands. Please upgrade perfiz-cli")
+	}
+	if summary.Statistics == nil {
+		return nil, errors.New(summaryFile + ": no statistics in summary")
+	}
+	return summary, nil
+}
+
+// AddTags tags the run of a summary, for example as a baseline to keep. Tags it
+// already has are not repeated.
+func AddTags(summaryFile string, tags []string) error {
+	if info, statErr := os.Stat(summaryFile); statErr == nil && info.IsDir() {
+		summaryFile = filepath.Join(summaryFile, SUMMARY_REPORT)
+	}
+	summary, readErr := ReadSummary(summaryFile)
+	if readErr != nil {
+		return readErr
+	}
+	for _, tag := range tags {
+		if !contains(summary.Tags, tag) {
+			summary.Tags = append(summary.Tags, tag)
+		}
+	}
+	return WriteSummary(summaryFile, summary)
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package report
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/znsio/perfiz-cli/common/configuration"
+	"github.com/znsio/perfiz-cli/common/sla"
+)
+
+func Test_WriteSummary_CanBeReadBackFromRunDir(t *testing.T) {
+	runDir := t.TempDir()
+	results := sla.Evaluate([]configuration.Assertion{{Metric: configuration.P95_RESPONSE_TIME, LessThan: threshold(500)}}, statistics)
+	summary := &Summary{
+		RunId:           filepath.Base(runDir),
+		ConfigFile:      "perfiz.yml",
+		ResolvedConfig:  &configuration.PerfizConfig{KarateFeaturesDir: "karate-features", KarateEnv: "staging"},
+		KarateEnv:       "staging",
+		SimulationClass: "perfiz.BookingSimulation",
+		Start:           statistics.Start,
+		End:             statistics.End,
+		Statistics:      statistics,
+		Assertions:      results,
+	}
+	assert.Nil(t, WriteSummary(filepath.Join(runDir, SUMMARY_REPORT), summary))
+
+	readSummary, err := ReadSummary(runDir)
+	assert.Nil(t, err)
+	assert.Equal(t, SUMMARY_FORMAT_VERSION, readSummary.FormatVersion)
+	assert.Equal(t, "staging", readSummary.ResolvedConfig.KarateEnv)
+	assert.Equal(t, statistics.Requests, readSummary.Statistics.Requests)
+	assert.True(t, readSummary.Statistics.Start.Equal(statistics.Start))
+	assert.Equal(t, results, readSummary.Assertions)
+}
+
+func Test_WriteSummary_ListsNoAssertionsAsEmptyList(t *testing.T) {
+	summaryFile := filepath.Join(t.TempDir(), SUMMARY_REPORT)
+	assert.Nil(t, WriteSummary(summaryFile, &Summary{Statistics: statistics}))
+	summaryJson, _ := ioutil.ReadFile(summaryFile)
+	assert.Contains(t, string(summaryJson), `"assertions": []`)
+}
+
+func Test_ReadSummary_RejectsNewerFormatVersion(t *testing.T) {
+	summaryFile := filepath.Join(t.TempDir(), SUMMARY_REPORT)
+	ioutil.WriteFile(summaryFile, []byte(`{"formatVersion": 2, "statistics": {}}`), 0644)
+	_, err := ReadSummary(summaryFile)
+	assert.True(t, strings.Contains(err.Error(), "summary format version 2 is newer than this perfiz-cli understands"))
+}
+
+func Test_AddTags_TagsRunOnce(t *testing.T) {
+	runDir := t.TempDir()
+	assert.Nil(t, WriteSummary(filepath.Join(runDir, SUMMARY_REPORT), &Summary{Statistics: statistics, Tags: []string{"baseline"}}))
+	assert.Nil(t, AddTags(runDir, []string{"release-1.2", "baseline"}))
+	summary, err := ReadSummary(runDir)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"baseline", "release-1.2"}, summary.Tags)
+}
@@ -0,0 +1,113 @@
+package retention
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/znsio/perfiz-cli/common/configuration"
+	"github.com/znsio/perfiz-cli/common/gatling"
+	"github.com/znsio/perfiz-cli/common/path"
+	"github.com/znsio/perfiz-cli/common/report"
+)
+
+// Policy decides which runs to prune. A run is pruned only when no part of the policy
+// keeps it: it is not one of the KeepLast most recent runs, it is older than OlderThan
+// and, with KeepTagged, it has no tags. A zero KeepLast or OlderThan keeps nothing.
+type Policy struct {
+	KeepLast   int
+	OlderThan  time.Duration
+	KeepTagged bool
+}
+
+// Run is a run directory in the Gatling results folder.
+type Run struct {
+	Dir   string
+	Start time.Time
+	Tags  []string
+	Size  int64
+}
+
+func PolicyFrom(retention *configuration.Retention) (Policy, error) {
+	policy := Policy{KeepLast: retention.KeepLast, KeepTagged: retention.KeepTagged}
+	if retention.OlderThan != "" {
+		olderThan, err := configuration.ParseDuration(string(retention.OlderThan))
+		if err != nil {
+			return Policy{}, err
+		}
+		policy.OlderThan = olderThan
+	}
+	return policy, policy.Check()
+}
+
+// Check refuses a policy that would prune every run.
+func (policy Policy) Check() error {
+	if policy.KeepLast < 0 {
+		return errors.New("number of runs to keep must not be negative")
+	}
+	if policy.KeepLast == 0 && policy.OlderThan <= 0 {
+		return errors.New("number of runs to keep or age of runs to prune is required")
+	}
+	return nil
+}
+
+// Runs lists the runs in the results folder, oldest first, with the tags from their
+// summaries.
+func Runs(resultsDir string) ([]Run, error) {
+	runDirs, err := gatling.RunDirs(resultsDir)
+	if err != nil {
+		return nil, err
+	}
+	var runs []Run
+	for _, runDir := range runDirs {
+		run := Run{Dir: runDir, Start: gatling.RunStart(runDir), Size: path.Size(runDir)}
+		if _, summaryErr := os.Stat(filepath.Join(runDir, report.SUMMARY_REPORT)); summaryErr == nil {
+			if summary, readErr := report.ReadSummary(runDir); readErr == nil {
+				run.Tags = summary.Tags
+			}
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// Select returns the runs the policy prunes out of runs listed oldest first.
+func (policy Policy) Select(runs []Run, now time.Time) []Run {
+	var pruned []Run
+	for i, run := range runs {
+		if policy.KeepLast > 0 && i >= len(runs)-policy.KeepLast {
+			continue
+		}
+		if policy.OlderThan > 0 && now.Sub(run.Start) <= policy.OlderThan {
+			continue
+		}
+		if policy.KeepTagged && len(run.Tags) > 0 {
+			continue
+		}
+		pruned = append(pruned, run)
+	}
+	return pruned
+}
+
+// Prune removes the runs in the results folder the policy selects, and returns them.
+// With dryRun nothing is removed.
+func Prune(resultsDir string, policy Policy, now time.Time, dryRun bool) ([]Run, error) {
+	if err := policy.Check(); err != nil {
+		return nil, err
+	}
+	runs, runsErr := Runs(resultsDir)
+	if runsErr != nil {
+		return nil, runsErr
+	}
+	pruned := policy.Select(runs, now)
+	if dryRun {
+		return pruned, nil
+	}
+	for i, run := range pruned {
+		if err := os.RemoveAll(run.Dir); err != nil {
+			return pruned[:i], err
+		}
+	}
+	return pruned, nil
+}
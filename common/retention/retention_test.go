@@ -0,0 +1,85 @@
+package retention
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/znsio/perfiz-cli/common/configuration"
+	"github.com/znsio/perfiz-cli/common/gatling"
+	"github.com/znsio/perfiz-cli/common/report"
+)
+
+var now = time.Date(2021, 9, 30, 12, 0, 0, 0, time.UTC)
+
+func daysAgo(days int) time.Time {
+	return now.Add(-time.Duration(days) * 24 * time.Hour)
+}
+
+var runs = []Run{
+	{Dir: "run-1", Start: daysAgo(60), Tags: []string{"baseline"}},
+	{Dir: "run-2", Start: daysAgo(45)},
+	{Dir: "run-3", Start: daysAgo(20)},
+	{Dir: "run-4", Start: daysAgo(10)},
+	{Dir: "run-5", Start: daysAgo(1)},
+}
+
+func dirs(runs []Run) []string {
+	var runDirs []string
+	for _, run := range runs {
+		runDirs = append(runDirs, run.Dir)
+	}
+	return runDirs
+}
+
+func Test_Select_PrunesRunsNoPartOfPolicyKeeps(t *testing.T) {
+	assert.Equal(t, []string{"run-1", "run-2", "run-3"}, dirs(Policy{KeepLast: 2}.Select(runs, now)))
+	assert.Equal(t, []string{"run-1", "run-2"}, dirs(Policy{OlderThan: 30 * 24 * time.Hour}.Select(runs, now)))
+	assert.Equal(t, []string{"run-2"}, dirs(Policy{KeepLast: 2, OlderThan: 30 * 24 * time.Hour, KeepTagged: true}.Select(runs, now)))
+	assert.Empty(t, Policy{KeepLast: 10}.Select(runs, now))
+}
+
+func Test_PolicyFrom_RefusesToPruneEveryRun(t *testing.T) {
+	_, err := PolicyFrom(&configuration.Retention{KeepTagged: true})
+	assert.Equal(t, "number of runs to keep or age of runs to prune is required", err.Error())
+	policy, err := PolicyFrom(&configuration.Retention{OlderThan: "30d", KeepTagged: true})
+	assert.Nil(t, err)
+	assert.Equal(t, Policy{OlderThan: 30 * 24 * time.Hour, KeepTagged: true}, policy)
+}
+
+// createRun creates a run directory that started at a given time, as its RUN record
+// says, and was last modified now, as a copied run directory is.
+func createRun(t *testing.T, resultsDir string, name string, start time.Time, tags ...string) string {
+	runDir := filepath.Join(resultsDir, name)
+	assert.Nil(t, os.MkdirAll(runDir, 0755))
+	runRecord := "RUN\torg.znsio.perfiz.PerfizSimulation\tperfizsimulation\t" + strconv.FormatInt(start.UnixNano()/int64(time.Millisecond), 10) + "\t \t3.5.1\n"
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(runDir, gatling.SIMULATION_LOG), []byte(runRecord), 0644))
+	if len(tags) > 0 {
+		assert.Nil(t, report.WriteSummary(filepath.Join(runDir, report.SUMMARY_REPORT), &report.Summary{Statistics: &gatling.Statistics{}, Tags: tags}))
+	}
+	return runDir
+}
+
+func Test_Prune_RemovesSelectedRunsUnlessDryRun(t *testing.T) {
+	resultsDir := t.TempDir()
+	tagged := createRun(t, resultsDir, "perfizsimulation-1", daysAgo(60), "baseline")
+	old := createRun(t, resultsDir, "perfizsimulation-2", daysAgo(45))
+	recent := createRun(t, resultsDir, "perfizsimulation-3", daysAgo(1))
+	policy := Policy{OlderThan: 30 * 24 * time.Hour, KeepTagged: true}
+
+	pruned, err := Prune(resultsDir, policy, now, true)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{old}, dirs(pruned))
+	assert.DirExists(t, old)
+
+	pruned, err = Prune(resultsDir, policy, now, false)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{old}, dirs(pruned))
+	assert.NoDirExists(t, old)
+	assert.DirExists(t, tagged)
+	assert.DirExists(t, recent)
+}
@@ -0,0 +1,96 @@
+package sla
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/znsio/perfiz-cli/common/configuration"
+	"github.com/znsio/perfiz-cli/common/gatling"
+)
+
+type Result struct {
+	Assertion configuration.Assertion `json:"assertion"`
+	Actual    float64                 `json:"actual"`
+	Passed    bool                    `json:"passed"`
+	Message   string                  `json:"message,omitempty"`
+}
+
+// Evaluate checks every assertion against the statistics of a test run. An assertion
+// on a request that was never made fails, as it can not have held.
+func Evaluate(assertions []configuration.Assertion, statistics *gatling.Statistics) []Result {
+	var results []Result
+	for _, assertion := range assertions {
+		requestName := assertion.Request
+		if requestName == "" {
+			requestName = gatling.ALL_REQUESTS
+		}
+		requestStatistics, found := statistics.Request(requestName)
+		if !found {
+			results = append(results, Result{Assertion: assertion, Message: "request " + strconv.Quote(requestName) + " not found in results"})
+			continue
+		}
+		actual := MetricValue(assertion.Metric, requestStatistics)
+		result := Result{Assertion: assertion, Actual: actual, Passed: true}
+		if assertion.LessThan != nil && actual >= *assertion.LessThan {
+			result.Passed = false
+		}
+		if assertion.GreaterThan != nil && actual <= *assertion.GreaterThan {
+			result.Passed = false
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func MetricValue(metric configuration.AssertionMetric, statistics gatling.RequestStatistics) float64 {
+	switch metric {
+	case configuration.MIN_RESPONSE_TIME:
+		return float64(statistics.MinResponseTime)
+	case configuration.MEAN_RESPONSE_TIME:
+		return statistics.MeanResponseTime
+	case configuration.MAX_RESPONSE_TIME:
+		return float64(statistics.MaxResponseTime)
+	case configuration.P50_RESPONSE_TIME:
+		return float64(statistics.P50ResponseTime)
+	case configuration.P75_RESPONSE_TIME:
+		return float64(statistics.P75ResponseTime)
+	case configuration.P95_RESPONSE_TIME:
+		return float64(statistics.P95ResponseTime)
+	case configuration.P99_RESPONSE_TIME:
+		return float64(statistics.P99ResponseTime)
+	case configuration.ERROR_RATE:
+		return statistics.ErrorRate()
+	case configuration.THROUGHPUT:
+		return statistics.Throughput
+	}
+	return 0
+}
+
+func Failures(results []Result) int {
+	failures := 0
+	for _, result := range results {
+		if !result.Passed {
+			failures++
+		}
+	}
+	return failures
+}
+
+func WriteTable(writer io.Writer, results []Result) {
+	table := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "RESULT\tASSERTION\tACTUAL")
+	for _, result := range results {
+		outcome := "PASSED"
+		if !result.Passed {
+			outcome = "FAILED"
+		}
+		actual := strconv.FormatFloat(result.Actual, 'f', 2, 64)
+		if result.Message != "" {
+			actual = result.Message
+		}
+		fmt.Fprintln(table, outcome+"\t"+result.Assertion.String()+"\t"+actual)
+	}
+	table.Flush()
+}
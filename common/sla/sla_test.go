@@ -0,0 +1,52 @@
+package sla
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/znsio/perfiz-cli/common/configuration"
+	"github.com/znsio/perfiz-cli/common/gatling"
+)
+
+func threshold(value float64) *float64 {
+	return &value
+}
+
+var statistics = &gatling.Statistics{
+	Global: gatling.RequestStatistics{Name: gatling.ALL_REQUESTS, Count: 200, OK: 198, KO: 2, P95ResponseTime: 450, MaxResponseTime: 2500, Throughput: 40},
+	Requests: []gatling.RequestStatistics{
+		{Name: "GET /bookings", Count: 100, OK: 100, P95ResponseTime: 300, MaxResponseTime: 900, Throughput: 20},
+		{Name: "POST /bookings", Count: 100, OK: 98, KO: 2, P95ResponseTime: 600, MaxResponseTime: 2500, Throughput: 20},
+	},
+}
+
+func Test_Evaluate_ChecksAssertionsAgainstStatistics(t *testing.T) {
+	results := Evaluate([]configuration.Assertion{
+		{Metric: configuration.P95_RESPONSE_TIME, LessThan: threshold(500)},
+		{Metric: configuration.ERROR_RATE, LessThan: threshold(1.5)},
+		{Metric: configuration.MAX_RESPONSE_TIME, Request: "GET /bookings", LessThan: threshold(2000)},
+		{Metric: configuration.MAX_RESPONSE_TIME, Request: "POST /bookings", LessThan: threshold(2000)},
+		{Metric: configuration.THROUGHPUT, GreaterThan: threshold(30)},
+		{Metric: configuration.THROUGHPUT, Request: "DELETE /bookings", GreaterThan: threshold(1)},
+	}, statistics)
+	var passed []bool
+	for _, result := range results {
+		passed = append(passed, result.Passed)
+	}
+	assert.Equal(t, []bool{true, true, true, false, true, false}, passed)
+	assert.Equal(t, float64(1), results[1].Actual)
+	assert.Equal(t, 2, Failures(results))
+}
+
+func Test_WriteTable_ListsOutcomeOfEveryAssertion(t *testing.T) {
+	results := Evaluate([]configuration.Assertion{
+		{Metric: configuration.P95_RESPONSE_TIME, LessThan: threshold(500)},
+		{Metric: configuration.THROUGHPUT, Request: "DELETE /bookings", GreaterThan: threshold(1)},
+	}, statistics)
+	var table bytes.Buffer
+	WriteTable(&table, results)
+	assert.Equal(t, "RESULT  ASSERTION                              ACTUAL\n"+
+		"PASSED  p95ResponseTime of all requests < 500  450.00\n"+
+		"FAILED  throughput of DELETE /bookings > 1     request \"DELETE /bookings\" not found in results\n", table.String())
+}
@@ -0,0 +1,241 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/znsio/perfiz-cli/common/archive"
+	"github.com/znsio/perfiz-cli/common/path"
+)
+
+const (
+	MANIFEST = "perfiz-snapshot.json"
+	// MANIFEST_FORMAT_VERSION changes when snapshots are laid out differently, so that
+	// a snapshot is never restored by a CLI that does not understand it.
+	MANIFEST_FORMAT_VERSION = 1
+	SNAPSHOT_EXTENSION      = ".tar.gz"
+)
+
+var nameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Store is one of the data directories in a snapshot.
+type Store struct {
+	Name string `json:"name"`
+	Dir  string `json:"dir"`
+	Size int64  `json:"size"`
+}
+
+// Manifest describes a snapshot. It is the first entry of the snapshot archive.
+type Manifest struct {
+	FormatVersion int       `json:"formatVersion"`
+	Name          string    `json:"name"`
+	Created       time.Time `json:"created"`
+	CliVersion    string    `json:"cliVersion"`
+	Stores        []Store   `json:"stores"`
+}
+
+func (manifest *Manifest) Size() int64 {
+	var size int64
+	for _, store := range manifest.Stores {
+		size += store.Size
+	}
+	return size
+}
+
+func (manifest *Manifest) StoreNames() []string {
+	var names []string
+	for _, store := range manifest.Stores {
+		names = append(names, store.Name)
+	}
+	return names
+}
+
+func File(snapshotsDir string, name string) string {
+	return filepath.Join(snapshotsDir, name+SNAPSHOT_EXTENSION)
+}
+
+func CheckName(name string) error {
+	if !nameRegex.MatchString(name) {
+		return errors.New("invalid snapshot name " + strconv.Quote(name) + ". Use letters, digits, '.', '_' and '-'")
+	}
+	return nil
+}
+
+// Save archives the data directories of the stores that exist into a new snapshot.
+func Save(snapshotsDir string, name string, stores []Store, cliVersion string) (*Manifest, error) {
+	if err := CheckName(name); err != nil {
+		return nil, err
+	}
+	snapshotFile := File(snapshotsDir, name)
+	if _, err := os.Stat(snapshotFile); err == nil {
+		return nil, errors.New("snapshot " + name + " already exists")
+	}
+	manifest := &Manifest{FormatVersion: MANIFEST_FORMAT_VERSION, Name: name, Created: time.Now(), CliVersion: cliVersion}
+	for _, store := range stores {
+		if path.IsDir(store.Dir) {
+			store.Size = path.Size(store.Dir)
+			manifest.Stores = append(manifest.Stores, store)
+		}
+	}
+	if len(manifest.Stores) == 0 {
+		return nil, errors.New("no data to snapshot")
+	}
+	manifestJson, _ := json.MarshalIndent(manifest, "", "  ")
+
+	if err := os.MkdirAll(snapshotsDir, 0755); err != nil {
+		return nil, err
+	}
+	writer, createErr := archive.Create(snapshotFile)
+	if createErr != nil {
+		return nil, createErr
+	}
+	addErr := writer.AddBytes(MANIFEST, manifestJson)
+	for _, store := range manifest.Stores {
+		if addErr != nil {
+			break
+		}
+		addErr = writer.AddPath(store.Dir)
+	}
+	closeErr := writer.Close()
+	if addErr != nil || closeErr != nil {
+		os.Remove(snapshotFile)
+		if addErr != nil {
+			return nil, addErr
+		}
+		return nil, closeErr
+	}
+	return manifest, nil
+}
+
+func ReadManifest(snapshotFile string) (*Manifest, error) {
+	manifestJson, readErr := archive.ReadFile(snapshotFile, MANIFEST)
+	if readErr != nil {
+		return nil, readErr
+	}
+	manifest := &Manifest{}
+	if err := json.Unmarshal(manifestJson, manifest); err != nil {
+		return nil, errors.New(snapshotFile + ": " + err.Error())
+	}
+	if manifest.FormatVersion > MANIFEST_FORMAT_VERSION {
+		return nil, errors.New(snapshotFile + ": snapshot format version " + strconv.Itoa(manifest.FormatVersion) + " is newer than this perfiz-cli understands. Please upgrade perfiz-cli")
+	}
+	return manifest, nil
+}
+
+// List returns the manifests of the snapshots in the snapshots folder, oldest first.
+// Snapshots that can not be read are returned as errors alongside.
+func List(snapshotsDir string) ([]*Manifest, []error) {
+	entries, err := ioutil.ReadDir(snapshotsDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, []error{err}
+	}
+	var manifests []*Manifest
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), SNAPSHOT_EXTENSION) {
+			continue
+		}
+		manifest, readErr := ReadManifest(filepath.Join(snapshotsDir, entry.Name()))
+		if readErr != nil {
+			errs = append(errs, readErr)
+			continue
+		}
+		manifests = append(manifests, manifest)
+	}
+	sort.SliceStable(manifests, func(i, j int) bool { return manifests[i].Created.Before(manifests[j].Created) })
+	return manifests, errs
+}
+
+// Restore replaces the data directories of the stores in a snapshot with their content
+// in the snapshot. Data directories of stores that are not in the snapshot are left as
+// they are. Only the data directories of known stores are touched, whatever the
+// manifest says. The snapshot is extracted next to the data directories first, so that
+// a snapshot that can not be extracted leaves them as they were.
+func Restore(snapshotsDir string, name string, knownStores []Store) (*Manifest, error) {
+	if err := CheckName(name); err != nil {
+		return nil, err
+	}
+	snapshotFile := File(snapshotsDir, name)
+	manifest, manifestErr := ReadManifest(snapshotFile)
+	if manifestErr != nil {
+		return nil, manifestErr
+	}
+	for _, store := range manifest.Stores {
+		if !isKnown(store, knownStores) {
+			return nil, errors.New("snapshot " + name + " contains unknown data directory " + store.Dir)
+		}
+	}
+	if len(manifest.Stores) == 0 {
+		return manifest, nil
+	}
+	parentDir := filepath.Dir(filepath.Clean(manifest.Stores[0].Dir))
+	if err := os.MkdirAll(parentDir, 0755); err != nil {
+		return nil, err
+	}
+	stagingDir, tempErr := ioutil.TempDir(parentDir, ".restore-"+name+"-")
+	if tempErr != nil {
+		return nil, tempErr
+	}
+	defer os.RemoveAll(stagingDir)
+	err := archive.ExtractMatching(snapshotFile, stagingDir, func(name string) bool {
+		for _, store := range manifest.Stores {
+			storeDir := filepath.ToSlash(filepath.Clean(store.Dir))
+			if name == storeDir+"/" || strings.HasPrefix(name, storeDir+"/") {
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, store := range manifest.Stores {
+		if err := replace(store.Dir, filepath.Join(stagingDir, store.Dir), filepath.Join(stagingDir, "previous-"+strconv.Itoa(i))); err != nil {
+			return nil, err
+		}
+	}
+	return manifest, nil
+}
+
+// replace moves the extracted directory in place of dir, moving dir out of the way to
+// previousDir rather than deleting it before the extracted directory is in place.
+func replace(dir string, extractedDir string, previousDir string) error {
+	if !path.IsDir(extractedDir) {
+		if err := os.MkdirAll(extractedDir, 0755); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return err
+	}
+	if _, err := os.Lstat(dir); err == nil {
+		if err := os.Rename(dir, previousDir); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(extractedDir, dir); err != nil {
+		os.Rename(previousDir, dir)
+		return err
+	}
+	return nil
+}
+
+func isKnown(store Store, knownStores []Store) bool {
+	for _, knownStore := range knownStores {
+		if knownStore.Name == store.Name && filepath.Clean(knownStore.Dir) == filepath.Clean(store.Dir) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package snapshot
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/znsio/perfiz-cli/common/archive"
+)
+
+var stores = []Store{
+	{Name: "grafana", Dir: "./perfiz/grafana_data"},
+	{Name: "prometheus", Dir: "./perfiz/prometheus_data"},
+	{Name: "gatling", Dir: "./perfiz/gatling_data"},
+}
+
+func inProjectDir(t *testing.T) {
+	workingDir, _ := os.Getwd()
+	assert.Nil(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() { os.Chdir(workingDir) })
+	assert.Nil(t, os.MkdirAll("perfiz/grafana_data", 0755))
+	assert.Nil(t, ioutil.WriteFile("perfiz/grafana_data/grafana.db", []byte("rc1 dashboards"), 0644))
+	assert.Nil(t, os.MkdirAll("perfiz/prometheus_data/wal", 0755))
+	assert.Nil(t, ioutil.WriteFile("perfiz/prometheus_data/wal/00000001", []byte("rc1 metrics"), 0644))
+}
+
+func Test_Save_SnapshotsExistingDataThatRestoreBringsBack(t *testing.T) {
+	inProjectDir(t)
+	manifest, err := Save("perfiz/snapshots", "rc1", stores, "0.0.25")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"grafana", "prometheus"}, manifest.StoreNames())
+	assert.Equal(t, int64(25), manifest.Size())
+
+	assert.Nil(t, ioutil.WriteFile("perfiz/grafana_data/grafana.db", []byte("rc2 dashboards"), 0644))
+	assert.Nil(t, ioutil.WriteFile("perfiz/grafana_data/rc2.db", []byte("rc2"), 0644))
+	assert.Nil(t, os.MkdirAll("perfiz/gatling_data", 0755))
+
+	_, err = Restore("perfiz/snapshots", "rc1", stores)
+	assert.Nil(t, err)
+	content, _ := ioutil.ReadFile("perfiz/grafana_data/grafana.db")
+	assert.Equal(t, "rc1 dashboards", string(content))
+	assert.NoFileExists(t, "perfiz/grafana_data/rc2.db")
+	content, _ = ioutil.ReadFile("perfiz/prometheus_data/wal/00000001")
+	assert.Equal(t, "rc1 metrics", string(content))
+	assert.DirExists(t, "perfiz/gatling_data")
+	assert.NoFileExists(t, MANIFEST)
+}
+
+func Test_Save_RefusesExistingOrInvalidNames(t *testing.T) {
+	inProjectDir(t)
+	_, err := Save("perfiz/snapshots", "rc1", stores, "0.0.25")
+	assert.Nil(t, err)
+	_, err = Save("perfiz/snapshots", "rc1", stores, "0.0.25")
+	assert.Equal(t, "snapshot rc1 already exists", err.Error())
+	_, err = Save("perfiz/snapshots", "../rc1", stores, "0.0.25")
+	assert.Equal(t, "invalid snapshot name \"../rc1\". Use letters, digits, '.', '_' and '-'", err.Error())
+}
+
+func Test_Restore_RefusesUnknownDataDirectories(t *testing.T) {
+	inProjectDir(t)
+	_, err := Save("perfiz/snapshots", "rc1", stores, "0.0.25")
+	assert.Nil(t, err)
+	_, err = Restore("perfiz/snapshots", "rc1", stores[1:])
+	assert.Equal(t, "snapshot rc1 contains unknown data directory ./perfiz/grafana_data", err.Error())
+	assert.FileExists(t, "perfiz/grafana_data/grafana.db")
+}
+
+func Test_Restore_LeavesDataAloneWhenSnapshotCanNotBeExtracted(t *testing.T) {
+	inProjectDir(t)
+	assert.Nil(t, os.MkdirAll("perfiz/snapshots", 0755))
+	writer, createErr := archive.Create(File("perfiz/snapshots", "broken"))
+	assert.Nil(t, createErr)
+	assert.Nil(t, writer.AddBytes(MANIFEST, []byte(`{"formatVersion":1,"name":"broken","stores":[{"name":"grafana","dir":"./perfiz/grafana_data"}]}`)))
+	assert.Nil(t, writer.AddBytes("perfiz/grafana_data/grafana.db", []byte("broken dashboards")))
+	assert.Nil(t, writer.AddBytes("perfiz/grafana_data/../../../escaped", []byte("escaped")))
+	assert.Nil(t, writer.Close())
+
+	_, err := Restore("perfiz/snapshots", "broken", stores)
+	assert.NotNil(t, err)
+	content, _ := ioutil.ReadFile("perfiz/grafana_data/grafana.db")
+	assert.Equal(t, "rc1 dashboards", string(content))
+	stagingDirs, _ := filepath.Glob("perfiz/.restore-*")
+	assert.Empty(t, stagingDirs)
+}
+
+func Test_List_ReturnsManifestsOfSnapshots(t *testing.T) {
+	inProjectDir(t)
+	manifests, errs := List("perfiz/snapshots")
+	assert.Empty(t, manifests)
+	assert.Empty(t, errs)
+
+	_, err := Save("perfiz/snapshots", "rc1", stores, "0.0.25")
+	assert.Nil(t, err)
+	_, err = Save("perfiz/snapshots", "rc2", stores[:1], "0.0.25")
+	assert.Nil(t, err)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join("perfiz/snapshots", "broken.tar.gz"), []byte("not gzip"), 0644))
+
+	manifests, errs = List("perfiz/snapshots")
+	assert.Equal(t, 2, len(manifests))
+	assert.Equal(t, "rc1", manifests[0].Name)
+	assert.Equal(t, []string{"grafana"}, manifests[1].StoreNames())
+	assert.Equal(t, 1, len(errs))
+}
@@ -0,0 +1,99 @@
+package stream
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	COLOR_RESET = "\033[0m"
+	COLOR_RED   = "\033[31m"
+	COLOR_CYAN  = "\033[36m"
+)
+
+type Stream struct {
+	Name   string
+	Color  string
+	Reader io.Reader
+}
+
+// Streamer logs the lines of several streams, such as the stdout and stderr of a
+// container, as they arrive. Each line is prefixed with the name of its stream.
+type Streamer struct {
+	mutex  sync.Mutex
+	logger *log.Logger
+	color  bool
+	tee    io.Writer
+	onLine func(string)
+}
+
+func New(logger *log.Logger, color bool) *Streamer {
+	return &Streamer{logger: logger, color: color}
+}
+
+// TeeTo additionally writes every line, with a timestamp and without colors, to writer.
+func (streamer *Streamer) TeeTo(writer io.Writer) {
+	streamer.tee = writer
+}
+
+// OnLine registers a function that is called with every line of every stream. Calls
+// never overlap, so the function need not be safe for concurrent use.
+func (streamer *Streamer) OnLine(onLine func(string)) {
+	streamer.onLine = onLine
+}
+
+// Consume reads all streams concurrently until each of them is closed, so that a
+// stream that is not being read can not hold up the process writing to the others.
+func (streamer *Streamer) Consume(streams ...Stream) {
+	var waitGroup sync.WaitGroup
+	for _, stream := range streams {
+		waitGroup.Add(1)
+		go func(stream Stream) {
+			defer waitGroup.Done()
+			scanner := bufio.NewScanner(stream.Reader)
+			scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+			for scanner.Scan() {
+				streamer.writeLine(stream, scanner.Text())
+			}
+			if err := scanner.Err(); err != nil {
+				streamer.writeLine(stream, "Error reading "+stream.Name+": "+err.Error())
+				// Keep reading, as the process may not write to its other streams until
+				// this one is read.
+				io.Copy(ioutil.Discard, stream.Reader)
+			}
+		}(stream)
+	}
+	waitGroup.Wait()
+}
+
+func (streamer *Streamer) writeLine(stream Stream, line string) {
+	streamer.mutex.Lock()
+	defer streamer.mutex.Unlock()
+	prefix := "[" + stream.Name + "] "
+	if streamer.color && stream.Color != "" {
+		streamer.logger.Println(stream.Color + prefix + COLOR_RESET + line)
+	} else {
+		streamer.logger.Println(prefix + line)
+	}
+	if streamer.tee != nil {
+		io.WriteString(streamer.tee, time.Now().Format(time.RFC3339)+" "+prefix+line+"\n")
+	}
+	if streamer.onLine != nil {
+		streamer.onLine(line)
+	}
+}
+
+// ColorSupported tells whether the standard error, where the log is written, is a
+// terminal and the user has not opted out of colors with NO_COLOR.
+func ColorSupported() bool {
+	if _, noColor := os.LookupEnv("NO_COLOR"); noColor {
+		return false
+	}
+	stderrInfo, err := os.Stderr.Stat()
+	return err == nil && stderrInfo.Mode()&os.ModeCharDevice != 0
+}
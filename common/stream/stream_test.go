@@ -0,0 +1,71 @@
+package stream
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Consume_ReadsStreamsConcurrently(t *testing.T) {
+	var output bytes.Buffer
+	streamer := New(log.New(&output, "", 0), false)
+	var lines []string
+	streamer.OnLine(func(line string) { lines = append(lines, line) })
+
+	stdoutReader, stdoutWriter := io.Pipe()
+	stderrReader, stderrWriter := io.Pipe()
+	go func() {
+		// stderr is written and closed before anything is written to stdout
+		io.WriteString(stderrWriter, "compilation warning\n")
+		stderrWriter.Close()
+		io.WriteString(stdoutWriter, "simulation started\nsimulation finished\n")
+		stdoutWriter.Close()
+	}()
+	streamer.Consume(Stream{Name: "stdout", Reader: stdoutReader}, Stream{Name: "stderr", Color: COLOR_RED, Reader: stderrReader})
+
+	assert.Equal(t, "[stderr] compilation warning\n[stdout] simulation started\n[stdout] simulation finished\n", output.String())
+	assert.Equal(t, []string{"compilation warning", "simulation started", "simulation finished"}, lines)
+}
+
+func Test_Consume_ColorsPrefixesAndTeesPlainLines(t *testing.T) {
+	var output, tee bytes.Buffer
+	streamer := New(log.New(&output, "", 0), true)
+	streamer.TeeTo(&tee)
+	streamer.Consume(Stream{Name: "stderr", Color: COLOR_RED, Reader: strings.NewReader("error\n")})
+
+	assert.Equal(t, COLOR_RED+"[stderr] "+COLOR_RESET+"error\n", output.String())
+	assert.True(t, strings.HasSuffix(tee.String(), " [stderr] error\n"))
+}
+
+func Test_Consume_DrainsStreamWithLineTooLongToScan(t *testing.T) {
+	var output bytes.Buffer
+	streamer := New(log.New(&output, "", 0), false)
+	stdoutReader, stdoutWriter := io.Pipe()
+	stderrReader, stderrWriter := io.Pipe()
+	go func() {
+		// a single writer, as when stdout and stderr are split from one stream
+		io.WriteString(stdoutWriter, strings.Repeat("x", 2*1024*1024)+"\n")
+		io.WriteString(stderrWriter, "still writing\n")
+		io.WriteString(stdoutWriter, "after the long line\n")
+		stdoutWriter.Close()
+		stderrWriter.Close()
+	}()
+
+	consumed := make(chan bool)
+	go func() {
+		streamer.Consume(Stream{Name: "stdout", Reader: stdoutReader}, Stream{Name: "stderr", Reader: stderrReader})
+		close(consumed)
+	}()
+	select {
+	case <-consumed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Consume did not return")
+	}
+	assert.Contains(t, output.String(), "[stdout] Error reading stdout: bufio.Scanner: token too long\n")
+	assert.Contains(t, output.String(), "[stderr] still writing\n")
+}
@@ -0,0 +1,85 @@
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/otiai10/copy"
+	"github.com/znsio/perfiz-cli/common/constants"
+)
+
+const (
+	SIMULATIONS_DIR = "src/test/scala"
+	RESOURCES_DIR   = "src/test/resources"
+	CONFIG_FILE     = "perfiz-resolved.yml"
+	DIR_PREFIX      = "perfiz-workspace-"
+)
+
+// Workspace is where a test runs: a copy of PERFIZ_HOME with the Gatling simulations
+// and configuration of the project laid over it, so that a run neither changes
+// PERFIZ_HOME nor sees the files of another run.
+type Workspace struct {
+	Dir string
+}
+
+// Create copies PERFIZ_HOME into a new temporary directory. Its Maven repository, build
+// output and results are left out, and so are simulations that earlier versions of
+// perfiz-cli copied into PERFIZ_HOME and left behind.
+func Create(perfizHome string) (*Workspace, error) {
+	dir, tempErr := ioutil.TempDir("", DIR_PREFIX)
+	if tempErr != nil {
+		return nil, tempErr
+	}
+	options := copy.Options{
+		Skip: func(src string) (bool, error) {
+			relative, err := filepath.Rel(perfizHome, src)
+			return err == nil && isExcluded(filepath.ToSlash(relative)), nil
+		},
+	}
+	if err := copy.Copy(perfizHome, dir, options); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return &Workspace{Dir: dir}, nil
+}
+
+func isExcluded(relative string) bool {
+	switch relative {
+	case ".m2", ".git", "target", "results":
+		return true
+	}
+	return strings.HasPrefix(relative, SIMULATIONS_DIR+"/") && strings.HasSuffix(relative, ".scala") &&
+		!strings.Contains(path.Base(relative), "Perfiz")
+}
+
+// AddSimulations copies the Scala files of a directory of Gatling simulations.
+func (workspace *Workspace) AddSimulations(simulationsDir string) error {
+	onlyScalaFiles := copy.Options{
+		Skip: func(src string) (bool, error) {
+			info, err := os.Stat(src)
+			return err == nil && !info.IsDir() && !strings.HasSuffix(src, ".scala"), nil
+		},
+	}
+	return copy.Copy(simulationsDir, filepath.Join(workspace.Dir, SIMULATIONS_DIR), onlyScalaFiles)
+}
+
+func (workspace *Workspace) AddGatlingConf(gatlingConf string) error {
+	return copy.Copy(gatlingConf, filepath.Join(workspace.Dir, RESOURCES_DIR, constants.GATLING_CONF))
+}
+
+// Id tells the workspace apart from those of other runs.
+func (workspace *Workspace) Id() string {
+	return strings.TrimPrefix(filepath.Base(workspace.Dir), DIR_PREFIX)
+}
+
+// ConfigFile is where the run's resolved config is written.
+func (workspace *Workspace) ConfigFile() string {
+	return filepath.Join(workspace.Dir, CONFIG_FILE)
+}
+
+func (workspace *Workspace) Remove() error {
+	return os.RemoveAll(workspace.Dir)
+}
@@ -0,0 +1,76 @@
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, file string, content string) {
+	assert.Nil(t, os.MkdirAll(filepath.Dir(file), 0755))
+	assert.Nil(t, ioutil.WriteFile(file, []byte(content), 0644))
+}
+
+func createPerfizHome(t *testing.T) string {
+	perfizHome := t.TempDir()
+	writeFile(t, filepath.Join(perfizHome, "pom.xml"), "<project/>")
+	writeFile(t, filepath.Join(perfizHome, SIMULATIONS_DIR, "org/znsio/perfiz/PerfizSimulation.scala"), "perfiz")
+	writeFile(t, filepath.Join(perfizHome, SIMULATIONS_DIR, "LeftOverSimulation.scala"), "left over")
+	writeFile(t, filepath.Join(perfizHome, ".m2/repository/settings.xml"), "maven")
+	writeFile(t, filepath.Join(perfizHome, "target/classes/Compiled.class"), "compiled")
+	return perfizHome
+}
+
+func Test_Create_CopiesPerfizHomeWithoutMavenRepositoryBuildOutputOrLeftOverSimulations(t *testing.T) {
+	perfizHome := createPerfizHome(t)
+	workspace, err := Create(perfizHome)
+	assert.Nil(t, err)
+	defer workspace.Remove()
+
+	assert.FileExists(t, filepath.Join(workspace.Dir, "pom.xml"))
+	assert.FileExists(t, filepath.Join(workspace.Dir, SIMULATIONS_DIR, "org/znsio/perfiz/PerfizSimulation.scala"))
+	assert.NoFileExists(t, filepath.Join(workspace.Dir, SIMULATIONS_DIR, "LeftOverSimulation.scala"))
+	assert.NoDirExists(t, filepath.Join(workspace.Dir, ".m2"))
+	assert.NoDirExists(t, filepath.Join(workspace.Dir, "target"))
+}
+
+func Test_AddSimulations_CopiesOnlyScalaFilesAndLeavesPerfizHomeAlone(t *testing.T) {
+	perfizHome := createPerfizHome(t)
+	simulationsDir := t.TempDir()
+	writeFile(t, filepath.Join(simulationsDir, "checkout/CheckoutSimulation.scala"), "checkout")
+	writeFile(t, filepath.Join(simulationsDir, "checkout/README.md"), "readme")
+	gatlingConf := filepath.Join(t.TempDir(), "gatling.conf")
+	writeFile(t, gatlingConf, "gatling {}")
+	workspace, _ := Create(perfizHome)
+	defer workspace.Remove()
+
+	assert.Nil(t, workspace.AddSimulations(simulationsDir))
+	assert.Nil(t, workspace.AddGatlingConf(gatlingConf))
+
+	assert.FileExists(t, filepath.Join(workspace.Dir, SIMULATIONS_DIR, "checkout/CheckoutSimulation.scala"))
+	assert.NoFileExists(t, filepath.Join(workspace.Dir, SIMULATIONS_DIR, "checkout/README.md"))
+	assert.FileExists(t, filepath.Join(workspace.Dir, RESOURCES_DIR, "gatling.conf"))
+	assert.NoFileExists(t, filepath.Join(perfizHome, SIMULATIONS_DIR, "checkout/CheckoutSimulation.scala"))
+	assert.NoFileExists(t, filepath.Join(perfizHome, RESOURCES_DIR, "gatling.conf"))
+}
+
+func Test_Remove_DeletesWorkspace(t *testing.T) {
+	workspace, _ := Create(createPerfizHome(t))
+	assert.Nil(t, workspace.Remove())
+	assert.NoDirExists(t, workspace.Dir)
+}
+
+func Test_Id_DiffersBetweenWorkspaces(t *testing.T) {
+	perfizHome := createPerfizHome(t)
+	first, _ := Create(perfizHome)
+	defer first.Remove()
+	second, _ := Create(perfizHome)
+	defer second.Remove()
+
+	assert.NotEmpty(t, first.Id())
+	assert.NotEqual(t, first.Id(), second.Id())
+	assert.Equal(t, filepath.Join(first.Dir, CONFIG_FILE), first.ConfigFile())
+}